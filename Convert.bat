@@ -10,5 +10,13 @@ rem endlocal
 
 rem xlsx_parser.exe --dlua ./lua --dgo ./gen_config --map_sep "=" --array_sep "," --token_begin "[" --token_end "]" --indent "	" --excels "./tst1.xlsx=[tst1],./TST2.xlsx=[TST2,Tst3]" ./REST.xlsx
 
+rem xlsx_parser.exe --config project.yaml
+rem xlsx_parser.exe --input ./design/**/*.xlsx
+rem xlsx_parser.exe --force --config project.yaml
+rem xlsx_parser.exe watch --config project.yaml
+rem xlsx_parser.exe check --format junit --report report.xml --config project.yaml
+rem xlsx_parser.exe diff ./old_design ./design
+rem xlsx_parser.exe --dcs ./cs --djson ./json --excels "./tst1.xlsx=[tst1],./TST2.xlsx=[TST2,Tst3]" ./REST.xlsx
+
 xlsx_parser.exe --excels "./tst1.xlsx=[tst1],./TST2.xlsx=[TST2,Tst3]" ./REST.xlsx
 pause
@@ -0,0 +1,332 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// 增量导出缓存,记录输入文件、生成器配置及输出文件的 hash
+// excel文件及其引用的子标签(同一文件中)、配置、输出文件都未变化时跳过解析及输出
+// 外键引用的excel文件变化时重新检查引用
+type buildCache struct {
+	//生成器配置及程序本身的 hash,变化时全部重新导出
+	Options string `json:"options"`
+	//key=excel文件路径
+	Workbooks map[string]*cacheWorkbook `json:"workbooks"`
+	//所有excel文件共同的输出文件(模板工厂、加载器、.proto 等) key=文件路径 value=hash
+	Outputs map[string]string `json:"outputs"`
+}
+
+// excel文件的缓存记录
+type cacheWorkbook struct {
+	//文件内容的 hash,目录为其中所有单标签文件的 hash
+	Hash string `json:"hash"`
+	//自动查找到的表头有效的标签,未变化的文件再次自动查找时无需打开
+	Found []string `json:"found,omitempty"`
+	//导出的标签
+	Sheets []string `json:"sheets"`
+	//外键引用的其他excel文件,被引用的文件变化时重新检查
+	Refs []string `json:"refs,omitempty"`
+	//导出的标签使用的枚举,文件未变化时输出 enums.go 只需解析这些枚举标签
+	Enums []string `json:"enums,omitempty"`
+	//输出文件 key=文件路径 value=hash
+	Outputs map[string]string `json:"outputs"`
+}
+
+func newBuildCache() *buildCache {
+	return &buildCache{Workbooks: make(map[string]*cacheWorkbook), Outputs: make(map[string]string)}
+}
+
+// 读取缓存文件,文件不存在或无法解析时返回空缓存(全部重新导出)
+func loadBuildCache(pathfile string) *buildCache {
+	cache := newBuildCache()
+	data, err := ioutil.ReadFile(pathfile)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return newBuildCache()
+	}
+	if cache.Workbooks == nil {
+		cache.Workbooks = make(map[string]*cacheWorkbook)
+	}
+	for pathfile, entry := range cache.Workbooks {
+		if entry == nil {
+			delete(cache.Workbooks, pathfile)
+		}
+	}
+	return cache
+}
+
+func (c *buildCache) save(pathfile string) error {
+	data, err := json.MarshalIndent(c, "", "\t")
+	if err != nil {
+		return err
+	}
+	wc, err := openFile(pathfile)
+	if err != nil {
+		return err
+	}
+	defer wc.Close()
+	_, err = wc.Write(append(data, '\n'))
+	return err
+}
+
+// 生成器配置及程序本身的 hash,导出的文件列表不计入,由每个excel文件单独记录
+func (g *Generator) optionsHash() string {
+	cfg := g.Config
+	cfg.Excels, cfg.Inputs, cfg.Force, cfg.CacheFile = nil, nil, false, ""
+	h := sha1.New()
+	if err := json.NewEncoder(h).Encode(&cfg); err != nil {
+		return ""
+	}
+	//程序升级后输出格式可能变化
+	if exe, err := os.Executable(); err == nil {
+		if err := hashFile(h, exe); err != nil {
+			return ""
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashFile(h hash.Hash, pathfile string) error {
+	f, err := os.Open(pathfile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(h, f)
+	return err
+}
+
+// 文件内容的 hash,目录(csv、tsv 等单标签文件所在目录)为其中所有单标签文件及文件名的 hash
+func hashPath(pathfile string) (string, error) {
+	h := sha1.New()
+	info, err := os.Stat(pathfile)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		if err := hashFile(h, pathfile); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+	entries, err := ioutil.ReadDir(pathfile)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, "~$") || !isSheetFile(name) {
+			continue
+		}
+		fmt.Fprintf(h, "%s\x00", name)
+		if err := hashFile(h, filepath.Join(pathfile, name)); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// 输入文件的 hash,读取失败时返回空串(视为已变化,打开文件时记录错误)
+func (g *Generator) inputHash(pathfile string) string {
+	if hash, ok := g.hashes[pathfile]; ok {
+		return hash
+	}
+	hash, _ := hashPath(pathfile)
+	g.hashes[pathfile] = hash
+	return hash
+}
+
+// 自动查找时文件未变化则使用缓存中记录的标签,无需打开文件
+func (g *Generator) cachedFound(pathfile string) ([]string, bool) {
+	entry, ok := g.cache.Workbooks[pathfile]
+	if !ok || entry.Found == nil || g.cache.Options != g.options || entry.Hash == "" || entry.Hash != g.inputHash(pathfile) {
+		return nil, false
+	}
+	return entry.Found, true
+}
+
+// excel文件对应的所有输出文件
+func (g *Generator) workbookOutputs(pathfile string, sheetNames []string) []string {
+	className := path.Base(pathfile)
+	className = strings.TrimSuffix(className, path.Ext(className))
+	files := make([]string, 0)
+	if len(g.goSheets(sheetNames)) > 0 {
+		files = append(files, filepath.Join(g.goDir(), fmt.Sprintf("file_%s.go", className)))
+		if g.GoData {
+			files = append(files, filepath.Join(g.goDir(), fmt.Sprintf("data_%s.go", className)))
+		}
+	}
+	if luaSheets := g.luaSheets(sheetNames); len(luaSheets) > 0 {
+		files = append(files, filepath.Join(g.luaDir(), fmt.Sprintf("file_%s.lua", className)))
+		for _, sheetName := range luaSheets {
+			files = append(files, filepath.Join(g.luaDir(), fmt.Sprintf("sample_%s.lua", sheetName)))
+		}
+	}
+	for _, sheetName := range g.jsonSheets(sheetNames) {
+		files = append(files, filepath.Join(g.jsonDir(), fmt.Sprintf("sample_%s.json", sheetName)))
+	}
+	for _, sheetName := range g.msgpackSheets(sheetNames) {
+		files = append(files, filepath.Join(g.msgpackDir(), fmt.Sprintf("sample_%s.msgpack", sheetName)))
+	}
+	if len(g.csSheets(sheetNames)) > 0 {
+		files = append(files, filepath.Join(g.csDir(), fmt.Sprintf("file_%s.cs", className)))
+	}
+	if tsSheets := g.tsSheets(sheetNames); len(tsSheets) > 0 {
+		files = append(files, filepath.Join(g.tsDir(), fmt.Sprintf("file_%s.ts", className)))
+		for _, sheetName := range tsSheets {
+			files = append(files, filepath.Join(g.tsDir(), fmt.Sprintf("sample_%s.ts", sheetName)))
+		}
+	}
+	return files
+}
+
+// 所有excel文件共同的输出文件
+func (g *Generator) globalOutputs() []string {
+	jsonOn, msgpackOn := g.OutJSONPath != "", g.OutMsgpackPath != ""
+	files := []string{filepath.Join(g.goDir(), "global_map.go"), filepath.Join(g.goDir(), "enums.go")}
+	if jsonOn || msgpackOn {
+		files = append(files, filepath.Join(g.goDir(), "loader.go"))
+	}
+	if jsonOn {
+		files = append(files, filepath.Join(g.goDir(), "json_loader.go"))
+	}
+	if msgpackOn {
+		files = append(files, filepath.Join(g.goDir(), "msgpack_loader.go"))
+	}
+	msgpackSheets := 0
+	for _, sheetNames := range g.Excels {
+		msgpackSheets += len(g.msgpackSheets(sheetNames))
+	}
+	if msgpackSheets > 0 {
+		files = append(files, filepath.Join(g.luaDir(), "msgpack_loader.lua"))
+	}
+	if g.OutProtoPath != "" {
+		files = append(files, filepath.Join(g.protoDir(), "sample.proto"), filepath.Join(g.protoDir(), "sample.proto.lock"))
+		for _, sheetNames := range g.Excels {
+			for _, sheetName := range sheetNames {
+				files = append(files, filepath.Join(g.protoDir(), fmt.Sprintf("sample_%s.pb", sheetName)))
+			}
+		}
+	}
+	if g.OutCSPath != "" {
+		files = append(files, filepath.Join(g.csDir(), "global_map.cs"))
+		if jsonOn {
+			files = append(files, filepath.Join(g.csDir(), "json_loader.cs"))
+		}
+	}
+	if g.OutTSPath != "" {
+		files = append(files, filepath.Join(g.tsDir(), "index.ts"))
+	}
+	return files
+}
+
+// 输出文件与缓存记录不一致(文件列表变化、文件被删除或修改)
+func outputsChanged(outputs map[string]string, files []string) bool {
+	if len(outputs) != len(files) {
+		return true
+	}
+	for _, file := range files {
+		hash, ok := outputs[file]
+		if !ok {
+			return true
+		}
+		if h, err := hashPath(file); err != nil || h != hash {
+			return true
+		}
+	}
+	return false
+}
+
+func hashOutputs(files []string) (map[string]string, error) {
+	outputs := make(map[string]string, len(files))
+	for _, file := range files {
+		hash, err := hashPath(file)
+		if err != nil {
+			return nil, err
+		}
+		outputs[file] = hash
+	}
+	return outputs, nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// 需要重新导出的excel文件,任一文件需要重新导出或共同的输出文件变化时 global 为 true,重新输出共同的输出文件
+func (g *Generator) changedWorkbooks() (changed map[string]bool, global bool) {
+	changed = make(map[string]bool)
+	global = g.cache.Options != g.options || len(g.cache.Workbooks) != len(g.Excels) || outputsChanged(g.cache.Outputs, g.globalOutputs())
+	for pathfile, sheetNames := range g.Excels {
+		entry, ok := g.cache.Workbooks[pathfile]
+		if !ok || g.cache.Options != g.options || entry.Hash == "" || entry.Hash != g.inputHash(pathfile) ||
+			!equalStrings(entry.Sheets, sheetNames) || outputsChanged(entry.Outputs, g.workbookOutputs(pathfile, sheetNames)) {
+			changed[pathfile] = true
+			global = true
+		}
+	}
+	//引用了变化的excel文件的外键需要重新检查
+	for pathfile := range g.Excels {
+		entry, ok := g.cache.Workbooks[pathfile]
+		if !ok || changed[pathfile] {
+			continue
+		}
+		for _, ref := range entry.Refs {
+			if _, ok := g.Excels[ref]; !ok || changed[ref] {
+				changed[pathfile] = true
+				break
+			}
+		}
+	}
+	return
+}
+
+// 导出完成后记录新的缓存,未重新导出的excel文件沿用之前的记录
+func (g *Generator) saveCache(changed map[string]bool) error {
+	cache := newBuildCache()
+	cache.Options = g.options
+	for pathfile, sheetNames := range g.Excels {
+		entry := &cacheWorkbook{Hash: g.inputHash(pathfile), Found: g.found[pathfile], Sheets: sheetNames, Refs: g.refs[pathfile], Enums: g.enumNames[pathfile]}
+		if changed[pathfile] {
+			outputs, err := hashOutputs(g.workbookOutputs(pathfile, sheetNames))
+			if err != nil {
+				return err
+			}
+			entry.Outputs = outputs
+		} else {
+			entry.Outputs = g.cache.Workbooks[pathfile].Outputs
+			entry.Refs = g.cache.Workbooks[pathfile].Refs
+		}
+		cache.Workbooks[pathfile] = entry
+	}
+	outputs, err := hashOutputs(g.globalOutputs())
+	if err != nil {
+		return err
+	}
+	cache.Outputs = outputs
+	return cache.save(g.CacheFile)
+}
@@ -0,0 +1,207 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// Compound File Binary(OLE2) 格式,.xls 文件的容器
+// 只实现读取流所需的部分: FAT、MiniFAT、目录
+const (
+	cfbEndOfChain = 0xFFFFFFFE
+	//最大的普通扇区编号,更大的为特殊标记
+	cfbMaxSect = 0xFFFFFFFA
+)
+
+var cfbSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+type cfbFile struct {
+	data       []byte
+	sectorSize int
+	miniSize   int
+	//小于该大小的流保存在 mini stream 中
+	miniCutoff uint64
+	fat        []uint32
+	miniFat    []uint32
+	//root entry 的数据,即 mini stream
+	miniStream []byte
+	entries    []cfbEntry
+}
+
+// 目录项
+type cfbEntry struct {
+	name  string
+	typ   byte
+	start uint32
+	size  uint64
+}
+
+func openCFB(data []byte) (*cfbFile, error) {
+	if len(data) < 512 || !bytes.Equal(data[:8], cfbSignature) {
+		return nil, fmt.Errorf("not a compound file")
+	}
+	sectorShift := binary.LittleEndian.Uint16(data[0x1E:])
+	miniShift := binary.LittleEndian.Uint16(data[0x20:])
+	if sectorShift != 9 && sectorShift != 12 || miniShift != 6 {
+		return nil, fmt.Errorf("invalid compound file sector size")
+	}
+	f := &cfbFile{
+		data:       data,
+		sectorSize: 1 << sectorShift,
+		miniSize:   1 << miniShift,
+		miniCutoff: uint64(binary.LittleEndian.Uint32(data[0x38:])),
+	}
+	//FAT 所在扇区,前 109 个在文件头中,其余在 DIFAT 扇区链中
+	numFat := int(binary.LittleEndian.Uint32(data[0x2C:]))
+	fatSects := make([]uint32, 0, numFat)
+	for i := 0; i < 109 && len(fatSects) < numFat; i++ {
+		fatSects = append(fatSects, binary.LittleEndian.Uint32(data[0x4C+i*4:]))
+	}
+	perSect := f.sectorSize/4 - 1
+	seen := make(map[uint32]bool)
+	for difat := binary.LittleEndian.Uint32(data[0x44:]); len(fatSects) < numFat && difat <= cfbMaxSect; {
+		if seen[difat] {
+			return nil, fmt.Errorf("invalid compound file DIFAT chain")
+		}
+		seen[difat] = true
+		sect, err := f.sector(difat)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < perSect && len(fatSects) < numFat; i++ {
+			fatSects = append(fatSects, binary.LittleEndian.Uint32(sect[i*4:]))
+		}
+		difat = binary.LittleEndian.Uint32(sect[perSect*4:])
+	}
+	if len(fatSects) < numFat {
+		return nil, fmt.Errorf("invalid compound file DIFAT chain")
+	}
+	for _, id := range fatSects {
+		sect, err := f.sector(id)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < len(sect); i += 4 {
+			f.fat = append(f.fat, binary.LittleEndian.Uint32(sect[i:]))
+		}
+	}
+	dir, err := f.chain(binary.LittleEndian.Uint32(data[0x30:]), 0, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i+128 <= len(dir); i += 128 {
+		entry := dir[i : i+128]
+		nameLen := int(binary.LittleEndian.Uint16(entry[64:]))
+		if nameLen > 64 {
+			nameLen = 64
+		}
+		units := make([]uint16, 0, nameLen/2)
+		for j := 0; j+1 < nameLen; j += 2 {
+			units = append(units, binary.LittleEndian.Uint16(entry[j:]))
+		}
+		f.entries = append(f.entries, cfbEntry{
+			name:  strings.TrimRight(string(utf16.Decode(units)), "\x00"),
+			typ:   entry[66],
+			start: binary.LittleEndian.Uint32(entry[116:]),
+			size:  binary.LittleEndian.Uint64(entry[120:]),
+		})
+	}
+	if len(f.entries) == 0 || f.entries[0].typ != 5 {
+		return nil, fmt.Errorf("invalid compound file root entry")
+	}
+	//512 字节扇区的版本 3 文件中 size 高 32 位可能未初始化
+	if f.sectorSize == 512 {
+		for i := range f.entries {
+			f.entries[i].size &= 0xFFFFFFFF
+		}
+	}
+	root := f.entries[0]
+	if root.start != cfbEndOfChain {
+		if f.miniStream, err = f.chain(root.start, root.size, false); err != nil {
+			return nil, err
+		}
+		miniFat, err := f.chain(binary.LittleEndian.Uint32(data[0x3C:]), 0, false)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+4 <= len(miniFat); i += 4 {
+			f.miniFat = append(f.miniFat, binary.LittleEndian.Uint32(miniFat[i:]))
+		}
+	}
+	return f, nil
+}
+
+func (f *cfbFile) sector(id uint32) ([]byte, error) {
+	off := (int64(id) + 1) * int64(f.sectorSize)
+	if id > cfbMaxSect || off+int64(f.sectorSize) > int64(len(f.data)) {
+		//最后一个扇区可能不完整
+		if id <= cfbMaxSect && off < int64(len(f.data)) {
+			sect := make([]byte, f.sectorSize)
+			copy(sect, f.data[off:])
+			return sect, nil
+		}
+		return nil, fmt.Errorf("invalid compound file sector %d", id)
+	}
+	return f.data[off : off+int64(f.sectorSize)], nil
+}
+
+// 读取扇区链,size 为 0 时读取整条链
+func (f *cfbFile) chain(start uint32, size uint64, mini bool) ([]byte, error) {
+	fat, unit := f.fat, f.sectorSize
+	if mini {
+		fat, unit = f.miniFat, f.miniSize
+	}
+	var buf bytes.Buffer
+	seen := make(map[uint32]bool)
+	for id := start; id != cfbEndOfChain; id = fat[id] {
+		if id > cfbMaxSect || int(id) >= len(fat) || seen[id] {
+			return nil, fmt.Errorf("invalid compound file sector chain")
+		}
+		seen[id] = true
+		if mini {
+			off := int(id) * unit
+			if off+unit > len(f.miniStream) {
+				return nil, fmt.Errorf("invalid compound file mini sector %d", id)
+			}
+			buf.Write(f.miniStream[off : off+unit])
+		} else {
+			sect, err := f.sector(id)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(sect)
+		}
+		if size > 0 && uint64(buf.Len()) >= size {
+			break
+		}
+	}
+	if uint64(buf.Len()) < size {
+		return nil, fmt.Errorf("invalid compound file stream size")
+	}
+	if size > 0 {
+		return buf.Bytes()[:size], nil
+	}
+	return buf.Bytes(), nil
+}
+
+// 读取指定名称的流,名称不区分大小写
+func (f *cfbFile) stream(name string) ([]byte, bool, error) {
+	for _, entry := range f.entries {
+		if entry.typ != 2 || !strings.EqualFold(entry.name, name) {
+			continue
+		}
+		if entry.size == 0 {
+			return []byte{}, true, nil
+		}
+		data, err := f.chain(entry.start, entry.size, entry.size < f.miniCutoff)
+		return data, true, err
+	}
+	return nil, false, nil
+}
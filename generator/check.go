@@ -0,0 +1,241 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// 检查结果
+type CheckReport struct {
+	//检查的excel文件及标签 key=文件路径
+	Excels map[string][]string
+	//所有错误,按文件、标签、行、列排序
+	Diagnostics []*Diagnostic
+}
+
+// 只解析并检查所有excel文件,不使用缓存,不输出任何文件
+func Check(ctx context.Context, cfg *Config) (*CheckReport, error) {
+	g, err := NewGenerator(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return g.Check(ctx)
+}
+
+// 检查所有excel文件: 表头、类型、主键及 map key 重复、子标签数据行等,错误记录在报告中
+// 输出 TypeScript 时同时检查超出 number 范围的整数
+func (g *Generator) Check(ctx context.Context) (*CheckReport, error) {
+	workbooks, err := g.loadAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	//输出 TypeScript 时检查超出 number 范围的整数
+	g.checkTSSheets(workbooks, func(string) bool { return true })
+	return &CheckReport{Excels: g.Excels, Diagnostics: g.diag.List()}, nil
+}
+
+// 不使用缓存解析所有excel文件,错误记录到 diag 中
+func (g *Generator) loadAll(ctx context.Context) (map[string]*Workbook, error) {
+	g.diag = &Diagnostics{}
+	g.hashes = make(map[string]string)
+	g.found = make(map[string][]string)
+	g.refs = make(map[string][]string)
+	g.enumNames = make(map[string][]string)
+	g.cache = newBuildCache()
+	workbooks := g.discover()
+	g.checkClassNames()
+	all := func(string) bool { return true }
+	if err := g.loadWorkbooks(ctx, workbooks, all); err != nil {
+		return nil, err
+	}
+	g.checkRefs(workbooks, all)
+	g.collectEnums(workbooks, all)
+	return workbooks, nil
+}
+
+// 检查是否通过
+func (r *CheckReport) OK() bool {
+	return len(r.Diagnostics) == 0
+}
+
+// 检查的excel文件,包括只有错误记录的文件(如无法打开的文件)
+func (r *CheckReport) files() []string {
+	seen := make(map[string]bool)
+	files := make([]string, 0, len(r.Excels))
+	for pathfile := range r.Excels {
+		seen[pathfile] = true
+		files = append(files, pathfile)
+	}
+	for _, d := range r.Diagnostics {
+		if !seen[d.File] {
+			seen[d.File] = true
+			files = append(files, d.File)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// 输出文本报告,与导出失败时的错误信息一致
+func (r *CheckReport) WriteText(w io.Writer) error {
+	if !r.OK() {
+		_, err := fmt.Fprintln(w, (&DiagnosticsError{List: r.Diagnostics}).Error())
+		return err
+	}
+	sheets := 0
+	for _, sheetNames := range r.Excels {
+		sheets += len(sheetNames)
+	}
+	_, err := fmt.Fprintf(w, "ok, %d workbook(s) %d sheet(s) checked\n", len(r.Excels), sheets)
+	return err
+}
+
+type jsonCheckReport struct {
+	OK          bool                  `json:"ok"`
+	Errors      int                   `json:"errors"`
+	Workbooks   []jsonCheckWorkbook   `json:"workbooks"`
+	Diagnostics []jsonCheckDiagnostic `json:"diagnostics"`
+}
+
+type jsonCheckWorkbook struct {
+	File   string   `json:"file"`
+	Sheets []string `json:"sheets"`
+}
+
+// 行号、列号从 1 开始,没有具体位置时省略
+type jsonCheckDiagnostic struct {
+	File    string `json:"file"`
+	Sheet   string `json:"sheet,omitempty"`
+	Row     int    `json:"row,omitempty"`
+	Col     int    `json:"col,omitempty"`
+	Cell    string `json:"cell,omitempty"`
+	Column  string `json:"column,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Message string `json:"message"`
+}
+
+// 输出 JSON 报告
+func (r *CheckReport) WriteJSON(w io.Writer) error {
+	report := jsonCheckReport{
+		OK:          r.OK(),
+		Errors:      len(r.Diagnostics),
+		Workbooks:   make([]jsonCheckWorkbook, 0, len(r.Excels)),
+		Diagnostics: make([]jsonCheckDiagnostic, 0, len(r.Diagnostics)),
+	}
+	for _, pathfile := range r.files() {
+		if sheetNames, ok := r.Excels[pathfile]; ok {
+			report.Workbooks = append(report.Workbooks, jsonCheckWorkbook{File: pathfile, Sheets: sheetNames})
+		}
+	}
+	for _, d := range r.Diagnostics {
+		jd := jsonCheckDiagnostic{File: d.File, Sheet: d.Sheet, Column: d.Column, Type: d.Type, Message: d.Msg}
+		if d.Row >= 0 {
+			jd.Row = d.Row + 1
+		}
+		if d.Row >= 0 && d.Col >= 0 {
+			jd.Col = d.Col + 1
+			jd.Cell = CellName(d.Row, d.Col)
+		}
+		report.Diagnostics = append(report.Diagnostics, jd)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(&report)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// 输出 JUnit XML 报告,每个excel文件为一个 testsuite,每个标签为一个 testcase
+// 子标签及excel文件本身(无法打开、重名等)的错误单独作为 testcase
+func (r *CheckReport) WriteJUnit(w io.Writer) error {
+	//文件 -> 标签 -> 错误信息
+	failures := make(map[string]map[string][]string)
+	for _, d := range r.Diagnostics {
+		if failures[d.File] == nil {
+			failures[d.File] = make(map[string][]string)
+		}
+		failures[d.File][d.Sheet] = append(failures[d.File][d.Sheet], d.String())
+	}
+	report := junitTestSuites{Name: "xlsx_parser"}
+	for _, pathfile := range r.files() {
+		suite := junitTestSuite{Name: pathfile}
+		names := append([]string(nil), r.Excels[pathfile]...)
+		extra := make([]string, 0)
+		for sheetName := range failures[pathfile] {
+			found := false
+			for _, name := range names {
+				if name == sheetName {
+					found = true
+					break
+				}
+			}
+			if !found {
+				extra = append(extra, sheetName)
+			}
+		}
+		sort.Strings(extra)
+		for _, sheetName := range append(names, extra...) {
+			tc := junitTestCase{Name: sheetName, ClassName: pathfile}
+			if sheetName == "" {
+				tc.Name = "(workbook)"
+			}
+			if msgs := failures[pathfile][sheetName]; len(msgs) > 0 {
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("%d error(s)", len(msgs)),
+					Type:    "validation",
+					Text:    strings.Join(msgs, "\n"),
+				}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+			suite.Tests++
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(&report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
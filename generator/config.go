@@ -0,0 +1,154 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// 主键所在列
+const MAINKEY_INDEX = 0
+
+// 生成器配置
+type Config struct {
+	//golang 源文件输出目录,实际输出到该目录下的 sample 子目录
+	OutGoPath string
+	//lua 源文件输出目录,实际输出到该目录下的 sample 子目录
+	OutLuaPath string
+	//json 文件输出目录,实际输出到该目录下的 sample 子目录,为空时不输出
+	OutJSONPath string
+	//msgpack 文件输出目录,实际输出到该目录下的 sample 子目录,为空时不输出
+	OutMsgpackPath string
+	//protobuf 文件输出目录,实际输出到该目录下的 sample 子目录,为空时不输出
+	OutProtoPath string
+	//.proto 文件的 package
+	ProtoPackage string
+	//C# 源文件输出目录,实际输出到该目录下的 sample 子目录,为空时不输出
+	OutCSPath string
+	//C# 源文件的 namespace
+	CSNamespace string
+	//TypeScript 源文件输出目录,实际输出到该目录下的 sample 子目录,为空时不输出
+	OutTSPath string
+	//同时输出 golang 模板数据,init 时注册到 SampleFactory,无需 lua 运行时
+	GoData bool
+	//map key=value 分隔符
+	MapSeparator string
+	//数组内容分隔符
+	ArraySeparator string
+	//二维数组节点开始标记
+	ArraysTokenBegin string
+	//二维数组节点结束标记
+	ArraysTokenEnd string
+	//节点排版间隔
+	Indent string
+	//需要导出的excel文件 key=文件路径 value=导出的标签名
+	Excels map[string][]string
+	//自动查找的excel文件,支持目录及通配符,导出其中表头有效且名称不以 ! 或 # 开头的标签
+	Inputs []string
+	//标签导出选项 key=标签名
+	SheetOptions map[string]*SheetOption
+	//增量导出缓存文件,默认为 golang 输出目录下的 .xlsx_parser.cache
+	CacheFile string
+	//忽略缓存,重新导出所有excel文件
+	Force bool
+}
+
+// 标签导出选项
+type SheetOption struct {
+	//不输出 golang 结构体及模板工厂
+	SkipGo bool `json:"skip_go" yaml:"skip_go"`
+	//不输出 lua 文件
+	SkipLua bool `json:"skip_lua" yaml:"skip_lua"`
+	//不输出 json 文件
+	SkipJSON bool `json:"skip_json" yaml:"skip_json"`
+	//不输出 msgpack 文件
+	SkipMsgpack bool `json:"skip_msgpack" yaml:"skip_msgpack"`
+	//不输出 C# 类及模板表
+	SkipCS bool `json:"skip_cs" yaml:"skip_cs"`
+	//不输出 TypeScript 类型及数据
+	SkipTS bool `json:"skip_ts" yaml:"skip_ts"`
+}
+
+// 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		OutGoPath:        "./gen_config",
+		OutLuaPath:       "./lua",
+		MapSeparator:     "=",
+		ArraySeparator:   ",",
+		ArraysTokenBegin: "[",
+		ArraysTokenEnd:   "]",
+		Indent:           "\t",
+		ProtoPackage:     "sample",
+		CSNamespace:      "Sample",
+		Excels:           make(map[string][]string),
+		SheetOptions:     make(map[string]*SheetOption),
+	}
+}
+
+// 添加需要导出的excel文件及标签,标签名全局唯一
+func (c *Config) AddExcel(pathfile string, sheetNames ...string) error {
+	if c.Excels == nil {
+		c.Excels = make(map[string][]string)
+	}
+	if c.SheetOptions == nil {
+		c.SheetOptions = make(map[string]*SheetOption)
+	}
+	pathfile = CleanPath(pathfile)
+	//csv、tsv 文件以所在目录作为excel文件,文件名即标签名
+	if isSheetFile(pathfile) {
+		pathfile = path.Dir(pathfile)
+	}
+	for _, sheetName := range sheetNames {
+		if strings.TrimSpace(sheetName) == "" {
+			return fmt.Errorf("empty sheet name,file:%s", pathfile)
+		}
+		for _, v := range c.Excels {
+			for _, sn := range v {
+				if sn == sheetName {
+					return fmt.Errorf("duplicate sheet:%+v", sheetName)
+				}
+			}
+		}
+		c.Excels[pathfile] = append(c.Excels[pathfile], sheetName)
+	}
+	return nil
+}
+
+// 标签导出选项,未配置时返回默认选项
+func (c *Config) SheetOption(sheetName string) *SheetOption {
+	if option, ok := c.SheetOptions[sheetName]; ok && option != nil {
+		return option
+	}
+	return &SheetOption{}
+}
+
+// 检查配置合法性
+func (c *Config) Validate() error {
+	if c.MapSeparator == "" || c.ArraySeparator == "" || c.ArraysTokenBegin == "" || c.ArraysTokenEnd == "" {
+		return fmt.Errorf("invalid config,err:empty separator.")
+	}
+	if len(c.Excels) == 0 && len(c.Inputs) == 0 {
+		return fmt.Errorf("invalid config,err:no excel file.")
+	}
+	hash := make(map[string]bool)
+	for _, sheetNames := range c.Excels {
+		for _, sheetName := range sheetNames {
+			if hash[sheetName] {
+				return fmt.Errorf("duplicate sheet:%+v", sheetName)
+			}
+			hash[sheetName] = true
+		}
+	}
+	return nil
+}
+
+// 统一路径分隔符
+func CleanPath(pathfile string) string {
+	return strings.Replace(filepath.Clean(strings.TrimSpace(pathfile)), "\\", "/", -1)
+}
@@ -0,0 +1,304 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 字段约束,写在表头字段数据类型之后,以空格分隔,含空格的值用双引号包裹
+// eg: int32 min=1 max=100  string required regex=^icon_  []int len<=5  string unique oneof=a|b|c
+// required 之外的约束只检查非空单元格,min、max、regex、oneof 检查数组的每个元素及 map 的每个值
+// optional 不是约束,标记字段为可选: 空单元格表示未设置,与零值区分
+// key 不是约束,标记字段为主键,标记多个字段时按列顺序组成组合主键 eg: int32 key
+// index、group 标记字段建立索引: index 的值(包括空单元格的零值)在所有数据行中唯一,group 的值可以重复
+type Constraints struct {
+	//主键字段
+	Key bool
+	//唯一索引字段
+	Index bool
+	//分组索引字段
+	Group bool
+	//可选字段
+	Optional bool
+	//单元格不能为空
+	Required bool
+	//非空单元格的值在所有数据行中唯一,只用于基础数据类型
+	Unique bool
+	//取值范围,只用于数值类型
+	Min, Max *Value
+	//字符串需要匹配的正则表达式
+	Regex *regexp.Regexp
+	//数组、map 元素个数或字符串长度 eg: len<=5 => LenOp="<=" Len=5
+	LenOp string
+	Len   int
+	//可选值 key=值的文本形式
+	OneOf map[string]bool
+	//可选值的原始写法,用于错误信息
+	oneofSrc string
+}
+
+// 长度比较运算符,按匹配顺序排列
+var lenOps = []string{"<=", ">=", "<", ">", "="}
+
+// 解析字段约束,t 为字段数据类型,约束的值按字段数据类型解析
+func (wb *Workbook) parseConstraints(t *Type, s string) (*Constraints, error) {
+	tokens, err := splitConstraints(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	c := &Constraints{}
+	leaf := t.Leaf()
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		name, value := token, ""
+		if strings.HasPrefix(token, "len") {
+			for _, op := range lenOps {
+				if strings.HasPrefix(token[3:], op) {
+					name, value = "len", token[3+len(op):]
+					c.LenOp = op
+					break
+				}
+			}
+		} else if idx := strings.Index(token, "="); idx != -1 {
+			name, value = token[:idx], token[idx+1:]
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate constraint %s", name)
+		}
+		seen[name] = true
+		if value, err = unquoteConstraint(value); err != nil {
+			return nil, fmt.Errorf("invalid constraint %q,err:%v", token, err)
+		}
+		switch name {
+		case "key":
+			if t.Kind != TypeScalar {
+				return nil, fmt.Errorf("constraint key only for base type")
+			}
+			c.Key = true
+		case "index", "group":
+			if t.Kind != TypeScalar {
+				return nil, fmt.Errorf("constraint %s only for base type", name)
+			}
+			if name == "index" {
+				c.Index = true
+			} else {
+				c.Group = true
+			}
+		case "optional":
+			c.Optional = true
+		case "required":
+			c.Required = true
+		case "unique":
+			if t.Kind != TypeScalar {
+				return nil, fmt.Errorf("constraint unique only for base type")
+			}
+			c.Unique = true
+		case "min", "max":
+			if !leaf.IsInt() && !leaf.IsFloat() {
+				return nil, fmt.Errorf("constraint %s only for number type", name)
+			}
+			v, err := wb.decodeScalar(leaf, value)
+			if err != nil || value == "" {
+				return nil, fmt.Errorf("invalid constraint %q", token)
+			}
+			if name == "min" {
+				c.Min = v
+			} else {
+				c.Max = v
+			}
+		case "regex":
+			if leaf.Kind != TypeScalar || leaf.Name != "string" {
+				return nil, fmt.Errorf("constraint regex only for string type")
+			}
+			if c.Regex, err = regexp.Compile(value); err != nil {
+				return nil, fmt.Errorf("invalid constraint %q,err:%v", token, err)
+			}
+		case "len":
+			if t.Kind != TypeSlice && t.Kind != TypeMap && !(t.Kind == TypeScalar && t.Name == "string") {
+				return nil, fmt.Errorf("constraint len only for array, map or string type")
+			}
+			if c.Len, err = strconv.Atoi(value); err != nil || c.Len < 0 {
+				return nil, fmt.Errorf("invalid constraint %q", token)
+			}
+		case "oneof":
+			if leaf.Kind != TypeScalar {
+				return nil, fmt.Errorf("constraint oneof only for base type")
+			}
+			c.OneOf = make(map[string]bool)
+			c.oneofSrc = value
+			for _, item := range strings.Split(value, "|") {
+				v, err := wb.decodeScalar(leaf, item)
+				if err != nil {
+					return nil, fmt.Errorf("invalid constraint %q,err:%v", token, err)
+				}
+				c.OneOf[v.String()] = true
+			}
+		default:
+			return nil, fmt.Errorf("unknown constraint %q", token)
+		}
+	}
+	if c.Optional && c.Required {
+		return nil, fmt.Errorf("invalid constraint,optional field can not be required")
+	}
+	if c.Index && c.Group {
+		return nil, fmt.Errorf("invalid constraint,index field can not be group")
+	}
+	if c.Optional && (c.Index || c.Group) {
+		return nil, fmt.Errorf("invalid constraint,optional field can not be index or group")
+	}
+	if c.Min != nil && c.Max != nil && compareScalar(c.Min, c.Max) > 0 {
+		return nil, fmt.Errorf("invalid constraint,min %s greater than max %s", c.Min, c.Max)
+	}
+	return c, nil
+}
+
+// 以空格分隔约束,双引号中的空格不分隔
+func splitConstraints(s string) ([]string, error) {
+	tokens := make([]string, 0)
+	var buf strings.Builder
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && quoted && i+1 < len(s):
+			buf.WriteByte(c)
+			i++
+			buf.WriteByte(s[i])
+		case c == '"':
+			quoted = !quoted
+			buf.WriteByte(c)
+		case unicode.IsSpace(rune(c)) && !quoted:
+			if buf.Len() > 0 {
+				tokens = append(tokens, buf.String())
+				buf.Reset()
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unclosed quote in constraint %q", strings.TrimSpace(s))
+	}
+	if buf.Len() > 0 {
+		tokens = append(tokens, buf.String())
+	}
+	return tokens, nil
+}
+
+func unquoteConstraint(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) {
+		return strconv.Unquote(s)
+	}
+	return s, nil
+}
+
+// 比较两个数值
+func compareScalar(a, b *Value) int {
+	switch x := a.Scalar.(type) {
+	case int64:
+		y := b.Scalar.(int64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	case float64:
+		y := b.Scalar.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// 检查单元格数据,返回所有违反约束的错误,unique 由 Sheet 检查
+func (c *Constraints) check(raw string, v *Value) []error {
+	if strings.TrimSpace(raw) == "" {
+		if c.Required {
+			return []error{fmt.Errorf("empty value,required")}
+		}
+		return nil
+	}
+	errs := make([]error, 0)
+	if c.LenOp != "" {
+		n := len(v.List)
+		if s, ok := v.Scalar.(string); ok {
+			n = utf8.RuneCountInString(s)
+		}
+		ok := true
+		switch c.LenOp {
+		case "<=":
+			ok = n <= c.Len
+		case ">=":
+			ok = n >= c.Len
+		case "<":
+			ok = n < c.Len
+		case ">":
+			ok = n > c.Len
+		case "=":
+			ok = n == c.Len
+		}
+		if !ok {
+			errs = append(errs, fmt.Errorf("length %d out of range,need len%s%d", n, c.LenOp, c.Len))
+		}
+	}
+	return c.checkElem(v, errs)
+}
+
+// 检查基础数据类型的值,数组、map 逐个检查元素
+func (c *Constraints) checkElem(v *Value, errs []error) []error {
+	if v.Type.Kind != TypeScalar {
+		for _, elem := range v.List {
+			errs = c.checkElem(elem, errs)
+		}
+		return errs
+	}
+	if c.Min != nil && compareScalar(v, c.Min) < 0 {
+		errs = append(errs, fmt.Errorf("value %s less than min %s", v, c.Min))
+	}
+	if c.Max != nil && compareScalar(v, c.Max) > 0 {
+		errs = append(errs, fmt.Errorf("value %s greater than max %s", v, c.Max))
+	}
+	if s, ok := v.Scalar.(string); ok && c.Regex != nil && !c.Regex.MatchString(s) {
+		errs = append(errs, fmt.Errorf("value %q not match regex %s", s, c.Regex))
+	}
+	if c.OneOf != nil && !c.OneOf[v.String()] {
+		errs = append(errs, fmt.Errorf("value %q not in oneof %s", v.String(), c.oneofSrc))
+	}
+	return errs
+}
+
+// 检查数据行中字段的约束,unique、index 记录每个值第一次出现的行
+func (s *Sheet) checkConstraints(rowIdx int, field *Field, raw string, v *Value) []error {
+	c := field.Constraints
+	errs := c.check(raw, v)
+	if c.Index || (c.Unique && strings.TrimSpace(raw) != "") {
+		if s.unique == nil {
+			s.unique = make(map[int]map[string]int)
+		}
+		if s.unique[field.Index] == nil {
+			s.unique[field.Index] = make(map[string]int)
+		}
+		if pre, ok := s.unique[field.Index][v.String()]; ok && pre != rowIdx {
+			errs = append(errs, fmt.Errorf("duplicate value %q, first defined at %s", v.String(), CellName(pre, field.Col)))
+		} else {
+			s.unique[field.Index][v.String()] = rowIdx
+		}
+	}
+	return errs
+}
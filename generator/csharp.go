@@ -0,0 +1,547 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// 对应的 C# 数据类型,结构体加 S_ 前缀,数组为 List<T>,map 为 Dictionary<K,V>
+func csType(t *Type) string {
+	switch t.Kind {
+	case TypeSlice:
+		return fmt.Sprintf("List<%s>", csType(t.Elem))
+	case TypeMap:
+		return fmt.Sprintf("Dictionary<%s, %s>", csType(t.Key), csType(t.Elem))
+	case TypeStruct:
+		return "S_" + t.Name
+	}
+	switch t.Name {
+	case "int8":
+		return "sbyte"
+	case "int16":
+		return "short"
+	case "int32":
+		return "int"
+	case "int", "int64":
+		return "long"
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	}
+	return t.Name
+}
+
+// 字段的 C# 数据类型,可选的数值、bool 为可空类型,未设置时为 null
+func csFieldType(field *Field) string {
+	if field.Optional() && field.Type.Kind == TypeScalar && field.Type.Name != "string" {
+		return csType(field.Type) + "?"
+	}
+	return csType(field.Type)
+}
+
+// 从 json 解析出的对象 expr 转换为类型 t 的表达式,depth 用于生成不重名的 lambda 参数
+func csFromJSON(t *Type, expr string, depth int) string {
+	switch t.Kind {
+	case TypeSlice:
+		x := fmt.Sprintf("x%d", depth)
+		return fmt.Sprintf("SampleJson.ToList(%s, %s => %s)", expr, x, csFromJSON(t.Elem, x, depth+1))
+	case TypeMap:
+		k, x := fmt.Sprintf("k%d", depth), fmt.Sprintf("x%d", depth)
+		return fmt.Sprintf("SampleJson.ToDictionary(%s, %s => %s, %s => %s)", expr, k, csFromJSON(t.Key, k, depth+1), x, csFromJSON(t.Elem, x, depth+1))
+	case TypeStruct:
+		return fmt.Sprintf("S_%s.FromJson(%s)", t.Name, expr)
+	}
+	switch t.Name {
+	case "int", "int64":
+		return fmt.Sprintf("SampleJson.ToLong(%s)", expr)
+	case "int8", "int16", "int32":
+		return fmt.Sprintf("(%s)SampleJson.ToLong(%s)", csType(t), expr)
+	case "float32":
+		return fmt.Sprintf("(float)SampleJson.ToDouble(%s)", expr)
+	case "float64":
+		return fmt.Sprintf("SampleJson.ToDouble(%s)", expr)
+	case "bool":
+		return fmt.Sprintf("SampleJson.ToBool(%s)", expr)
+	}
+	return fmt.Sprintf("SampleJson.ToStr(%s)", expr)
+}
+
+// 字段注释,转义 xml 文档注释中的特殊字符
+func csSummary(desc string, indent string) string {
+	desc = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(strings.TrimSpace(desc))
+	if desc == "" {
+		return ""
+	}
+	lines := strings.Split(desc, "\n")
+	var buf strings.Builder
+	buf.WriteString(indent + "/// <summary>\n")
+	for _, line := range lines {
+		buf.WriteString(fmt.Sprintf("%s/// %s\n", indent, strings.TrimRight(line, "\r")))
+	}
+	buf.WriteString(indent + "/// </summary>\n")
+	return buf.String()
+}
+
+// 输出模板表 SF_<标签名>,json 为 true 时输出从 json 构建的方法
+// 组合主键以元组为 key,并输出按各主键获取的 Get
+func generateCSFactory(sheet *Sheet, outputf func(s string), json bool) {
+	tmpl := template.Must(template.New("codeCSFactoryTemplate").Parse(`
+	public partial class SF_{{.Name}} : Dictionary<{{.KeyType}}, S_{{.Name}}>
+	{
+		//获取模板数据(请勿在模板数据上修改数据) sid 数据类型={{.KeyType}}
+		public S_{{.Name}} Get({{.KeyType}} sid)
+		{
+			S_{{.Name}} s;
+			return TryGetValue(sid, out s) ? s : null;
+		}
+{{- if .Params}}
+
+		//按组合主键获取模板数据(请勿在模板数据上修改数据)
+		public S_{{.Name}} Get({{.Params}})
+		{
+			return Get({{.Args}});
+		}
+{{- end}}
+{{- if .JSON}}
+
+		//从 sample_{{.Name}}.json 解析出的对象构建
+		public static SF_{{.Name}} FromJson(object o)
+		{
+			var f = new SF_{{.Name}}();
+{{.Loops}}			{
+				var s = S_{{.Name}}.FromJson({{.Var}}.Value);
+				f[{{.KeyExpr}}] = s;
+			}
+			return f;
+		}
+{{- end}}
+	}
+`))
+	keyType, keyExpr := csType(sheet.Key.Type), "s.P_"+sheet.Key.Name
+	params, args := "", ""
+	loops, kv := "\t\t\tforeach (var kv in SampleJson.ToObject(o))\n", "kv"
+	if sheet.Composite() {
+		types := make([]string, 0, len(sheet.Keys))
+		values := make([]string, 0, len(sheet.Keys))
+		ps := make([]string, 0, len(sheet.Keys))
+		as := make([]string, 0, len(sheet.Keys))
+		loops, kv = "", "o"
+		for i, key := range sheet.Keys {
+			types = append(types, csType(key.Type))
+			values = append(values, "s.P_"+key.Name)
+			ps = append(ps, fmt.Sprintf("%s P_%s", csType(key.Type), key.Name))
+			as = append(as, "P_"+key.Name)
+			//组合主键的数据按主键逐层嵌套
+			src := kv
+			if i > 0 {
+				src += ".Value"
+			}
+			kv = fmt.Sprintf("kv%d", i)
+			loops += fmt.Sprintf("\t\t\tforeach (var %s in SampleJson.ToObject(%s))\n", kv, src)
+		}
+		keyType = "(" + strings.Join(types, ", ") + ")"
+		keyExpr = "(" + strings.Join(values, ", ") + ")"
+		params = strings.Join(ps, ", ")
+		args = "(" + strings.Join(as, ", ") + ")"
+	}
+	var bs bytes.Buffer
+	if err := tmpl.Execute(&bs, struct {
+		Name    string
+		KeyType string
+		KeyExpr string
+		Params  string
+		Args    string
+		Loops   string
+		Var     string
+		JSON    bool
+	}{sheet.Name, keyType, keyExpr, params, args, loops, kv, json}); err != nil {
+		panic(err)
+	}
+	outputf(bs.String())
+}
+
+// 输出标签对应的类,返回需要继续输出的子标签
+func generateCSFromXLSXFile(sheet *Sheet, outputf func(s string), parsedSheetMap map[string]bool, json bool) (addParseSheetArray []string) {
+	outputf(fmt.Sprintf("\n\tpublic partial class S_%s\n\t{\n", sheet.Name))
+	for _, field := range sheet.Fields {
+		outputf(csSummary(field.Desc, "\t\t"))
+		outputf(fmt.Sprintf("\t\tpublic %s P_%s;\n", csFieldType(field), field.Name))
+		if leaf := field.Type.Leaf(); leaf.Kind == TypeStruct && !parsedSheetMap[leaf.Name] {
+			parsedSheetMap[leaf.Name] = true
+			addParseSheetArray = append(addParseSheetArray, leaf.Name)
+		}
+	}
+	if json {
+		outputf("\n\t\t//从 json 解析出的对象构建,缺少的字段为默认值\n")
+		outputf(fmt.Sprintf("\t\tpublic static S_%s FromJson(object o)\n\t\t{\n", sheet.Name))
+		outputf("\t\t\tvar obj = SampleJson.ToObject(o);\n")
+		outputf(fmt.Sprintf("\t\t\tvar s = new S_%s();\n", sheet.Name))
+		outputf("\t\t\tobject v;\n")
+		for _, field := range sheet.Fields {
+			outputf(fmt.Sprintf("\t\t\tif (obj.TryGetValue(\"P_%s\", out v))\n", field.Name))
+			outputf(fmt.Sprintf("\t\t\t\ts.P_%s = %s;\n", field.Name, csFromJSON(field.Type, "v", 0)))
+		}
+		outputf("\t\t\treturn s;\n\t\t}\n")
+	}
+	outputf("\t}\n")
+	return
+}
+
+// 输出所有模板表的静态实例 Samples.SF_<标签名>
+func generateCSMap(outputf func(s string), namespace string, sheetNames []string) {
+	tmpl := template.Must(template.New("codeCSMapTemplate").Parse(`//Code generated by xlsx-parser.
+//source: github.com/zxfonline/xlsx_parser
+//DO NOT EDIT!
+
+namespace {{.Namespace}}
+{
+	//所有模板表
+	public static partial class Samples
+	{
+{{- range .Sheets}}
+		public static SF_{{.}} SF_{{.}} = new SF_{{.}}();
+{{- end}}
+	}
+}
+`))
+	var bs bytes.Buffer
+	if err := tmpl.Execute(&bs, struct {
+		Namespace string
+		Sheets    []string
+	}{namespace, sheetNames}); err != nil {
+		panic(err)
+	}
+	outputf(bs.String())
+}
+
+// 输出 Samples.LoadJSON 及 json 解析,不依赖第三方库
+func generateCSJSONLoader(outputf func(s string), namespace string, sheetNames []string) {
+	tmpl := template.Must(template.New("codeCSJSONLoaderTemplate").Parse(`//Code generated by xlsx-parser.
+//source: github.com/zxfonline/xlsx_parser
+//DO NOT EDIT!
+
+using System;
+using System.Collections.Generic;
+using System.Globalization;
+using System.IO;
+using System.Text;
+
+namespace {{.Namespace}}
+{
+	public static partial class Samples
+	{
+		//从 dir 目录下的 sample_<标签名>.json 加载所有模板数据
+		public static void LoadJSON(string dir)
+		{
+			LoadJSON(name => File.ReadAllText(Path.Combine(dir, name), Encoding.UTF8));
+		}
+
+		//read 根据文件名 sample_<标签名>.json 返回文件内容,Unity 中可使用 Resources.Load<TextAsset>(...).text
+		public static void LoadJSON(Func<string, string> read)
+		{
+{{- range .Sheets}}
+			SF_{{.}} = SF_{{.}}.FromJson(SampleJson.Parse(read("sample_{{.}}.json")));
+{{- end}}
+		}
+	}
+
+	//json 解析,对象为 Dictionary<string, object>,数组为 List<object>,数字保留原始文本以免丢失 long 的精度
+	public static class SampleJson
+	{
+		public sealed class Number
+		{
+			public readonly string Raw;
+
+			public Number(string raw)
+			{
+				Raw = raw;
+			}
+		}
+
+		public static object Parse(string text)
+		{
+			var p = new Parser(text);
+			var v = p.Value();
+			p.SkipSpace();
+			if (p.Pos != text.Length)
+			{
+				throw p.Error("unexpected data after json value");
+			}
+			return v;
+		}
+
+		public static Dictionary<string, object> ToObject(object o)
+		{
+			var obj = o as Dictionary<string, object>;
+			if (obj == null)
+			{
+				throw new FormatException("json object expected");
+			}
+			return obj;
+		}
+
+		public static List<T> ToList<T>(object o, Func<object, T> elem)
+		{
+			var list = o as List<object>;
+			if (list == null)
+			{
+				throw new FormatException("json array expected");
+			}
+			var result = new List<T>(list.Count);
+			foreach (var e in list)
+			{
+				result.Add(elem(e));
+			}
+			return result;
+		}
+
+		//json 对象的 key 为字符串,按 map key 类型转换
+		public static Dictionary<K, V> ToDictionary<K, V>(object o, Func<object, K> key, Func<object, V> elem)
+		{
+			var result = new Dictionary<K, V>();
+			foreach (var kv in ToObject(o))
+			{
+				result[key(kv.Key)] = elem(kv.Value);
+			}
+			return result;
+		}
+
+		public static long ToLong(object o)
+		{
+			var n = o as Number;
+			return long.Parse(n != null ? n.Raw : ToStr(o), NumberStyles.Integer, CultureInfo.InvariantCulture);
+		}
+
+		public static double ToDouble(object o)
+		{
+			var n = o as Number;
+			return double.Parse(n != null ? n.Raw : ToStr(o), NumberStyles.Float, CultureInfo.InvariantCulture);
+		}
+
+		public static bool ToBool(object o)
+		{
+			if (o is bool)
+			{
+				return (bool)o;
+			}
+			return bool.Parse(ToStr(o));
+		}
+
+		public static string ToStr(object o)
+		{
+			var s = o as string;
+			if (s == null)
+			{
+				throw new FormatException("json string expected");
+			}
+			return s;
+		}
+
+		private sealed class Parser
+		{
+			private readonly string text;
+			public int Pos;
+
+			public Parser(string text)
+			{
+				this.text = text;
+			}
+
+			public FormatException Error(string msg)
+			{
+				return new FormatException(string.Format("{0} at {1}", msg, Pos));
+			}
+
+			public void SkipSpace()
+			{
+				while (Pos < text.Length && char.IsWhiteSpace(text[Pos]))
+				{
+					Pos++;
+				}
+			}
+
+			private void Expect(char c)
+			{
+				SkipSpace();
+				if (Pos >= text.Length || text[Pos] != c)
+				{
+					throw Error(string.Format("'{0}' expected", c));
+				}
+				Pos++;
+			}
+
+			private bool Literal(string s)
+			{
+				if (string.CompareOrdinal(text, Pos, s, 0, s.Length) != 0)
+				{
+					return false;
+				}
+				Pos += s.Length;
+				return true;
+			}
+
+			public object Value()
+			{
+				SkipSpace();
+				if (Pos >= text.Length)
+				{
+					throw Error("unexpected end of json");
+				}
+				var c = text[Pos];
+				switch (c)
+				{
+					case '{':
+						return Object();
+					case '[':
+						return Array();
+					case '"':
+						return String();
+				}
+				if (Literal("true"))
+				{
+					return true;
+				}
+				if (Literal("false"))
+				{
+					return false;
+				}
+				if (Literal("null"))
+				{
+					return null;
+				}
+				var start = Pos;
+				while (Pos < text.Length && "+-0123456789.eE".IndexOf(text[Pos]) >= 0)
+				{
+					Pos++;
+				}
+				if (start == Pos)
+				{
+					throw Error("invalid json value");
+				}
+				return new Number(text.Substring(start, Pos - start));
+			}
+
+			private Dictionary<string, object> Object()
+			{
+				var obj = new Dictionary<string, object>();
+				Expect('{');
+				SkipSpace();
+				if (Pos < text.Length && text[Pos] == '}')
+				{
+					Pos++;
+					return obj;
+				}
+				while (true)
+				{
+					SkipSpace();
+					var key = String();
+					Expect(':');
+					obj[key] = Value();
+					SkipSpace();
+					if (Pos < text.Length && text[Pos] == ',')
+					{
+						Pos++;
+						continue;
+					}
+					Expect('}');
+					return obj;
+				}
+			}
+
+			private List<object> Array()
+			{
+				var list = new List<object>();
+				Expect('[');
+				SkipSpace();
+				if (Pos < text.Length && text[Pos] == ']')
+				{
+					Pos++;
+					return list;
+				}
+				while (true)
+				{
+					list.Add(Value());
+					SkipSpace();
+					if (Pos < text.Length && text[Pos] == ',')
+					{
+						Pos++;
+						continue;
+					}
+					Expect(']');
+					return list;
+				}
+			}
+
+			private string String()
+			{
+				Expect('"');
+				var sb = new StringBuilder();
+				while (Pos < text.Length)
+				{
+					var c = text[Pos++];
+					if (c == '"')
+					{
+						return sb.ToString();
+					}
+					if (c != '\\')
+					{
+						sb.Append(c);
+						continue;
+					}
+					if (Pos >= text.Length)
+					{
+						break;
+					}
+					c = text[Pos++];
+					switch (c)
+					{
+						case 'b':
+							sb.Append('\b');
+							break;
+						case 'f':
+							sb.Append('\f');
+							break;
+						case 'n':
+							sb.Append('\n');
+							break;
+						case 'r':
+							sb.Append('\r');
+							break;
+						case 't':
+							sb.Append('\t');
+							break;
+						case 'u':
+							if (Pos + 4 > text.Length)
+							{
+								throw Error("invalid unicode escape");
+							}
+							sb.Append((char)Convert.ToInt32(text.Substring(Pos, 4), 16));
+							Pos += 4;
+							break;
+						default:
+							sb.Append(c);
+							break;
+					}
+				}
+				throw Error("unterminated json string");
+			}
+		}
+	}
+}
+`))
+	var bs bytes.Buffer
+	if err := tmpl.Execute(&bs, struct {
+		Namespace string
+		Sheets    []string
+	}{namespace, sheetNames}); err != nil {
+		panic(err)
+	}
+	outputf(bs.String())
+}
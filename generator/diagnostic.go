@@ -0,0 +1,136 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 标签或数据行存在错误,具体错误已记录到 Diagnostics 中
+var errInvalid = errors.New("invalid data, see diagnostics")
+
+// 一条错误信息及其所在位置
+type Diagnostic struct {
+	File  string
+	Sheet string
+	//行号、列号,从 0 开始,-1 表示没有具体位置
+	Row int
+	Col int
+	//所在字段名及数据类型
+	Column string
+	Type   string
+	Msg    string
+}
+
+// 单元格坐标 eg: C17
+func CellName(row, col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row+1)
+}
+
+// eg: file.xlsx!Sheet!C17 (column Name, type []int): msg
+func (d *Diagnostic) String() string {
+	var buf strings.Builder
+	buf.WriteString(d.File)
+	if d.Sheet != "" {
+		buf.WriteString("!" + d.Sheet)
+	}
+	if d.Row >= 0 && d.Col >= 0 {
+		buf.WriteString("!" + CellName(d.Row, d.Col))
+	} else if d.Row >= 0 {
+		buf.WriteString(fmt.Sprintf("!%d", d.Row+1))
+	}
+	if d.Column != "" && d.Type != "" {
+		buf.WriteString(fmt.Sprintf(" (column %s, type %s)", d.Column, d.Type))
+	} else if d.Column != "" {
+		buf.WriteString(fmt.Sprintf(" (column %s)", d.Column))
+	} else if d.Type != "" {
+		buf.WriteString(fmt.Sprintf(" (type %s)", d.Type))
+	}
+	buf.WriteString(": " + d.Msg)
+	return buf.String()
+}
+
+// 错误收集器,相同的错误只记录一次,可并发使用
+type Diagnostics struct {
+	sync.Mutex
+	list []*Diagnostic
+	seen map[string]bool
+}
+
+// 记录一条错误
+func (ds *Diagnostics) Add(d *Diagnostic) {
+	ds.Lock()
+	defer ds.Unlock()
+	if ds.seen == nil {
+		ds.seen = make(map[string]bool)
+	}
+	key := d.String()
+	if ds.seen[key] {
+		return
+	}
+	ds.seen[key] = true
+	ds.list = append(ds.list, d)
+}
+
+// 已记录的错误,按文件、标签、行、列排序
+func (ds *Diagnostics) List() []*Diagnostic {
+	ds.Lock()
+	defer ds.Unlock()
+	list := make([]*Diagnostic, len(ds.list))
+	copy(list, ds.list)
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Sheet != b.Sheet {
+			return a.Sheet < b.Sheet
+		}
+		if a.Row != b.Row {
+			return a.Row < b.Row
+		}
+		return a.Col < b.Col
+	})
+	return list
+}
+
+// 错误数量
+func (ds *Diagnostics) Len() int {
+	ds.Lock()
+	defer ds.Unlock()
+	return len(ds.list)
+}
+
+// 所有错误合并为一个 error,没有错误时返回 nil
+func (ds *Diagnostics) Err() error {
+	list := ds.List()
+	if len(list) == 0 {
+		return nil
+	}
+	return &DiagnosticsError{List: list}
+}
+
+// 导出失败时返回的错误,包含所有错误信息
+type DiagnosticsError struct {
+	List []*Diagnostic
+}
+
+func (e *DiagnosticsError) Error() string {
+	msgs := make([]string, 0, len(e.List)+1)
+	for _, d := range e.List {
+		msgs = append(msgs, d.String())
+	}
+	msgs = append(msgs, fmt.Sprintf("%d error(s)", len(e.List)))
+	return strings.Join(msgs, "\n")
+}
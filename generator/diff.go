@@ -0,0 +1,312 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 差异类型
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+	//字段类型变化
+	DiffRetyped = "retyped"
+)
+
+// 两个版本的excel文件按标签、字段、主键比较的差异
+type DiffReport struct {
+	//按标签名排序,只包含有差异的标签
+	Sheets []*SheetDiff `json:"sheets"`
+}
+
+// 标签的差异
+type SheetDiff struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	OldFile string `json:"old_file,omitempty"`
+	NewFile string `json:"new_file,omitempty"`
+	//新增、删除及类型变化的字段
+	Columns []*ColumnDiff `json:"columns,omitempty"`
+	//新增、删除及数据变化的行
+	Rows []*RowDiff `json:"rows,omitempty"`
+}
+
+// 字段的差异
+type ColumnDiff struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	OldType string `json:"old_type,omitempty"`
+	NewType string `json:"new_type,omitempty"`
+}
+
+// 数据行的差异,以主键对应
+type RowDiff struct {
+	Key    string `json:"key"`
+	Status string `json:"status"`
+	//新增、删除的行的所有数据
+	Values string `json:"values,omitempty"`
+	//数据变化的字段,只比较两个版本都存在的字段
+	Fields []*FieldDiff `json:"fields,omitempty"`
+}
+
+// 字段数据的变化
+type FieldDiff struct {
+	Name string `json:"name"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// 比较两个版本的excel文件(目录或文件,按自动查找方式导出所有表头有效的标签)
+// 任一版本存在错误时返回 *DiagnosticsError
+func Diff(ctx context.Context, cfg *Config, oldPath, newPath string) (*DiffReport, error) {
+	oldSheets, err := loadVersion(ctx, cfg, oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newSheets, err := loadVersion(ctx, cfg, newPath)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(oldSheets)+len(newSheets))
+	for name := range oldSheets {
+		names = append(names, name)
+	}
+	for name := range newSheets {
+		if _, ok := oldSheets[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	report := &DiffReport{Sheets: make([]*SheetDiff, 0)}
+	for _, name := range names {
+		oldSheet, newSheet := oldSheets[name], newSheets[name]
+		switch {
+		case oldSheet == nil:
+			report.Sheets = append(report.Sheets, &SheetDiff{Name: name, Status: DiffAdded, NewFile: newSheet.wb.Path})
+		case newSheet == nil:
+			report.Sheets = append(report.Sheets, &SheetDiff{Name: name, Status: DiffRemoved, OldFile: oldSheet.wb.Path})
+		default:
+			diff, err := diffSheet(oldSheet, newSheet)
+			if err != nil {
+				return nil, err
+			}
+			if diff != nil {
+				report.Sheets = append(report.Sheets, diff)
+			}
+		}
+	}
+	return report, nil
+}
+
+// 解析一个版本的所有标签 key=标签名
+func loadVersion(ctx context.Context, cfg *Config, pathfile string) (map[string]*Sheet, error) {
+	c := *cfg
+	c.Excels, c.Inputs = nil, []string{pathfile}
+	g, err := NewGenerator(&c)
+	if err != nil {
+		return nil, err
+	}
+	workbooks, err := g.loadAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.diag.Err(); err != nil {
+		return nil, err
+	}
+	sheets := make(map[string]*Sheet)
+	for pathfile, sheetNames := range g.Excels {
+		for _, sheetName := range sheetNames {
+			sheet, err := workbooks[pathfile].Sheet(sheetName)
+			if err != nil {
+				return nil, err
+			}
+			sheets[sheetName] = sheet
+		}
+	}
+	return sheets, nil
+}
+
+// 比较同名标签,没有差异时返回 nil
+func diffSheet(oldSheet, newSheet *Sheet) (*SheetDiff, error) {
+	diff := &SheetDiff{Name: newSheet.Name, Status: DiffChanged, OldFile: oldSheet.wb.Path, NewFile: newSheet.wb.Path}
+	newFields := make(map[string]*Field, len(newSheet.Fields))
+	for _, field := range newSheet.Fields {
+		newFields[field.Name] = field
+	}
+	//两个版本都存在的字段 old -> new
+	common := make([][2]*Field, 0, len(oldSheet.Fields))
+	oldFields := make(map[string]bool, len(oldSheet.Fields))
+	for _, field := range oldSheet.Fields {
+		oldFields[field.Name] = true
+		nf, ok := newFields[field.Name]
+		if !ok {
+			diff.Columns = append(diff.Columns, &ColumnDiff{Name: field.Name, Status: DiffRemoved, OldType: field.Type.String()})
+			continue
+		}
+		if nf.Type.String() != field.Type.String() {
+			diff.Columns = append(diff.Columns, &ColumnDiff{Name: field.Name, Status: DiffRetyped, OldType: field.Type.String(), NewType: nf.Type.String()})
+		}
+		common = append(common, [2]*Field{field, nf})
+	}
+	for _, field := range newSheet.Fields {
+		if !oldFields[field.Name] {
+			diff.Columns = append(diff.Columns, &ColumnDiff{Name: field.Name, Status: DiffAdded, NewType: field.Type.String()})
+		}
+	}
+	oldRecords, err := oldSheet.Records()
+	if err != nil {
+		return nil, err
+	}
+	newRecords, err := newSheet.Records()
+	if err != nil {
+		return nil, err
+	}
+	oldIndex := make(map[string]*Record, len(oldRecords))
+	for _, record := range oldRecords {
+		oldIndex[record.KeyString()] = record
+	}
+	newIndex := make(map[string]*Record, len(newRecords))
+	for _, record := range newRecords {
+		newIndex[record.KeyString()] = record
+	}
+	for _, record := range oldRecords {
+		if _, ok := newIndex[record.KeyString()]; !ok {
+			diff.Rows = append(diff.Rows, &RowDiff{Key: record.KeyString(), Status: DiffRemoved, Values: diffRecord(record)})
+		}
+	}
+	for _, record := range newRecords {
+		if _, ok := oldIndex[record.KeyString()]; !ok {
+			diff.Rows = append(diff.Rows, &RowDiff{Key: record.KeyString(), Status: DiffAdded, Values: diffRecord(record)})
+		}
+	}
+	for _, record := range newRecords {
+		old, ok := oldIndex[record.KeyString()]
+		if !ok {
+			continue
+		}
+		row := &RowDiff{Key: record.KeyString(), Status: DiffChanged}
+		for _, fields := range common {
+			ov, nv := diffValue(old.Values[fields[0].Index]), diffValue(record.Values[fields[1].Index])
+			if ov != nv {
+				row.Fields = append(row.Fields, &FieldDiff{Name: fields[1].Name, Old: ov, New: nv})
+			}
+		}
+		if len(row.Fields) > 0 {
+			diff.Rows = append(diff.Rows, row)
+		}
+	}
+	if len(diff.Columns) == 0 && len(diff.Rows) == 0 {
+		return nil, nil
+	}
+	return diff, nil
+}
+
+// 数据的文本形式,用于比较及输出
+// 结构体以 标签名(主键) 表示,引用的数据行的变化在子标签的差异中体现,未设置的可选字段为 nil
+func diffValue(v *Value) string {
+	if v == nil {
+		return "nil"
+	}
+	switch v.Type.Kind {
+	case TypeScalar:
+		if s, ok := v.Scalar.(string); ok {
+			return strconv.Quote(s)
+		}
+		return v.String()
+	case TypeStruct:
+		return fmt.Sprintf("%s(%s)", v.Record.Sheet.Name, diffValue(v.Record.Key()))
+	}
+	items := make([]string, 0, len(v.List))
+	for i, elem := range v.List {
+		if v.Type.Kind == TypeMap {
+			items = append(items, diffValue(v.Keys[i])+"="+diffValue(elem))
+		} else {
+			items = append(items, diffValue(elem))
+		}
+	}
+	if v.Type.Kind == TypeMap {
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+func diffRecord(record *Record) string {
+	items := make([]string, 0, len(record.Sheet.Fields))
+	for i, field := range record.Sheet.Fields {
+		items = append(items, field.Name+": "+diffValue(record.Values[i]))
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
+
+// 没有差异
+func (r *DiffReport) Empty() bool {
+	return len(r.Sheets) == 0
+}
+
+var diffMarks = map[string]string{DiffAdded: "+", DiffRemoved: "-", DiffChanged: "~", DiffRetyped: "~"}
+
+// 输出文本报告 eg:
+//
+//	~ sheet Item (old/item.xlsx -> new/item.xlsx)
+//	  + column Weight float32
+//	  ~ column Price int32 -> int64
+//	  - row 1003 {ID: 1003, Name: "Axe", ...}
+//	  ~ row 1001
+//	      Name: "Sword" -> "Long Sword"
+func (r *DiffReport) WriteText(w io.Writer) error {
+	var buf bytes.Buffer
+	if r.Empty() {
+		buf.WriteString("no differences\n")
+	}
+	for _, sheet := range r.Sheets {
+		switch sheet.Status {
+		case DiffAdded:
+			buf.WriteString(fmt.Sprintf("+ sheet %s (%s)\n", sheet.Name, sheet.NewFile))
+		case DiffRemoved:
+			buf.WriteString(fmt.Sprintf("- sheet %s (%s)\n", sheet.Name, sheet.OldFile))
+		default:
+			buf.WriteString(fmt.Sprintf("~ sheet %s (%s -> %s)\n", sheet.Name, sheet.OldFile, sheet.NewFile))
+		}
+		for _, column := range sheet.Columns {
+			switch column.Status {
+			case DiffAdded:
+				buf.WriteString(fmt.Sprintf("  + column %s %s\n", column.Name, column.NewType))
+			case DiffRemoved:
+				buf.WriteString(fmt.Sprintf("  - column %s %s\n", column.Name, column.OldType))
+			default:
+				buf.WriteString(fmt.Sprintf("  ~ column %s %s -> %s\n", column.Name, column.OldType, column.NewType))
+			}
+		}
+		for _, row := range sheet.Rows {
+			if row.Status != DiffChanged {
+				buf.WriteString(fmt.Sprintf("  %s row %s %s\n", diffMarks[row.Status], row.Key, row.Values))
+				continue
+			}
+			buf.WriteString(fmt.Sprintf("  ~ row %s\n", row.Key))
+			for _, field := range row.Fields {
+				buf.WriteString(fmt.Sprintf("      %s: %s -> %s\n", field.Name, field.Old, field.New))
+			}
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// 输出 JSON 报告
+func (r *DiffReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	return enc.Encode(r)
+}
@@ -0,0 +1,222 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 自动查找时支持的文件扩展名,由 RegisterSource 注册
+var workbookExts = make(map[string]bool)
+
+// 展开输入路径,目录递归查找所有excel文件,支持 ** 匹配任意层目录
+// eg: ./design  ./design/*.xlsx  ./design/**/*.xlsx
+func ExpandInput(pattern string) ([]string, error) {
+	pattern = CleanPath(pattern)
+	if info, err := os.Stat(pattern); err == nil {
+		if !info.IsDir() {
+			if isSheetFile(pattern) {
+				return []string{path.Dir(pattern)}, nil
+			}
+			return []string{pattern}, nil
+		}
+		return walkWorkbooks(pattern, func(string) bool { return true })
+	}
+	segs := strings.Split(pattern, "/")
+	//不含通配符的前缀目录作为查找起点
+	root := make([]string, 0, len(segs))
+	for _, seg := range segs {
+		if strings.ContainsAny(seg, "*?[") {
+			break
+		}
+		root = append(root, seg)
+	}
+	if len(root) == len(segs) {
+		return nil, fmt.Errorf("no found input %s", pattern)
+	}
+	dir := strings.Join(root, "/")
+	if dir == "" {
+		if len(segs) > 0 && segs[0] == "" {
+			dir = "/"
+		} else {
+			dir = "."
+		}
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid input pattern %s,err:%v", pattern, err)
+	}
+	return walkWorkbooks(dir, func(pathfile string) bool {
+		rel, err := filepath.Rel(dir, pathfile)
+		if err != nil {
+			return false
+		}
+		return matchGlob(segs[len(root):], strings.Split(filepath.ToSlash(rel), "/"))
+	})
+}
+
+// 递归查找目录下满足 match 的excel文件,忽略 excel 临时文件 ~$xxx.xlsx
+// csv、tsv 文件返回其所在目录
+func walkWorkbooks(dir string, match func(pathfile string) bool) ([]string, error) {
+	files := make([]string, 0)
+	found := make(map[string]bool)
+	err := filepath.Walk(dir, func(pathfile string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		pathfile = CleanPath(pathfile)
+		name := path.Base(pathfile)
+		if strings.HasPrefix(name, "~$") || !workbookExts[strings.ToLower(path.Ext(name))] {
+			return nil
+		}
+		if !match(pathfile) {
+			return nil
+		}
+		//csv、tsv 所在目录作为一个excel文件
+		if isSheetFile(pathfile) {
+			pathfile = path.Dir(pathfile)
+		}
+		if !found[pathfile] {
+			found[pathfile] = true
+			files = append(files, pathfile)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// 按目录层级匹配,** 匹配零或多层目录
+func matchGlob(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchGlob(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchGlob(pattern[1:], name[1:])
+}
+
+// 自动查找时是否导出该标签: 标签名不以 ! 或 # 开头,且表头有效
+// 主键列为第一个标记 key 的列,没有标记时为第一列
+// 只按表头形状判断,其他表头错误及主键、数据错误在导出时记录
+func exportable(sheet SourceSheet) bool {
+	if r, _ := utf8.DecodeRuneInString(sheet.Name()); r == '!' || r == '#' {
+		return false
+	}
+	if sheet.MaxRow() < HEAD_ROWS {
+		return false
+	}
+	col := MAINKEY_INDEX
+	for i := 0; i < sheet.MaxCol(1); i++ {
+		if keyColumn(sheet, i) {
+			col = i
+			break
+		}
+	}
+	att_name, _ := cellValue(sheet, 2, col)
+	att_type, _ := cellValue(sheet, 1, col)
+	if strings.TrimSpace(att_name) == "" {
+		return false
+	}
+	t, _, _, err := parseColumnType(strings.TrimSpace(att_type))
+	return err == nil && t.Kind == TypeScalar
+}
+
+// 字段数据类型之后是否标记了 key
+func keyColumn(sheet SourceSheet, col int) bool {
+	att_type, _ := cellValue(sheet, 1, col)
+	_, _, rest, err := parseColumnType(strings.TrimSpace(att_type))
+	if err != nil {
+		return false
+	}
+	tokens, _ := splitConstraints(rest)
+	for _, token := range tokens {
+		if token == "key" {
+			return true
+		}
+	}
+	return false
+}
+
+// 展开 Inputs 并将找到的标签加入导出列表,返回已打开的excel文件
+// 与已配置标签重名的标签记录错误,未变化的文件使用缓存中记录的标签不打开
+func (g *Generator) discover() map[string]*Workbook {
+	workbooks := make(map[string]*Workbook)
+	seen := make(map[string]bool)
+	//标签名 -> 所在文件
+	owners := make(map[string]string)
+	excels := make(map[string][]string, len(g.configured))
+	for pathfile, sheetNames := range g.configured {
+		for _, sheetName := range sheetNames {
+			owners[sheetName] = pathfile
+		}
+		excels[pathfile] = append([]string(nil), sheetNames...)
+	}
+	for _, input := range g.Inputs {
+		files, err := ExpandInput(input)
+		if err != nil {
+			g.diag.Add(&Diagnostic{File: input, Row: -1, Col: -1, Msg: err.Error()})
+			continue
+		}
+		for _, pathfile := range files {
+			if seen[pathfile] {
+				continue
+			}
+			seen[pathfile] = true
+			//文件未变化时使用缓存中的标签,无需打开
+			found, ok := g.cachedFound(pathfile)
+			if !ok {
+				wb, err := g.openWorkbook(pathfile)
+				if err != nil {
+					g.diag.Add(&Diagnostic{File: pathfile, Row: -1, Col: -1, Msg: err.Error()})
+					continue
+				}
+				workbooks[pathfile] = wb
+				found = make([]string, 0)
+				for _, sheet_root := range wb.file.Sheets() {
+					if exportable(sheet_root) {
+						found = append(found, sheet_root.Name())
+					}
+				}
+			}
+			g.found[pathfile] = found
+			for _, sheetName := range found {
+				if owner, ok := owners[sheetName]; ok {
+					if owner != pathfile {
+						g.diag.Add(&Diagnostic{File: pathfile, Sheet: sheetName, Row: -1, Col: -1, Msg: fmt.Sprintf("duplicate sheet:%s, also in %s", sheetName, owner)})
+					}
+					continue
+				}
+				owners[sheetName] = pathfile
+				excels[pathfile] = append(excels[pathfile], sheetName)
+			}
+		}
+	}
+	g.Excels = excels
+	return workbooks
+}
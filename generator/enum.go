@@ -0,0 +1,275 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 枚举定义,由excel中与枚举同名的标签定义
+// 标签第一行为列名 name、value、desc(可省略),之后每行一个枚举值
+// eg: enum:Quality => 标签 Quality
+//
+//	name   | value | desc
+//	Common | 1     | 普通
+//	Epic   | 4     | 史诗
+type Enum struct {
+	Name   string
+	Values []*EnumValue
+	//名称 -> 值
+	index map[string]int64
+}
+
+// 枚举值
+type EnumValue struct {
+	Name  string
+	Value int64
+	Desc  string
+}
+
+// 获取枚举定义,错误已记录时返回 errInvalid
+func (wb *Workbook) Enum(enumName string) (*Enum, error) {
+	if enum, ok := wb.enums[enumName]; ok {
+		if enum == nil {
+			return nil, errInvalid
+		}
+		return enum, nil
+	}
+	sheet_root, ok := wb.file.Sheet(enumName)
+	if !ok {
+		return nil, fmt.Errorf("no enum sheet %s available", enumName)
+	}
+	enum, ok := wb.parseEnum(enumName, sheet_root)
+	if !ok {
+		wb.enums[enumName] = nil
+		return nil, errInvalid
+	}
+	wb.enums[enumName] = enum
+	return enum, nil
+}
+
+// 解析枚举标签,所有错误记录后返回是否成功
+func (wb *Workbook) parseEnum(enumName string, sheet_root SourceSheet) (*Enum, bool) {
+	cols := map[string]int{"name": -1, "value": -1, "desc": -1}
+	for i := 0; i < sheet_root.MaxCol(0); i++ {
+		title, err := cellValue(sheet_root, 0, i)
+		if err != nil {
+			wb.report(enumName, 0, i, "", "", err)
+			return nil, false
+		}
+		if col, ok := cols[strings.ToLower(strings.TrimSpace(title))]; ok && col == -1 {
+			cols[strings.ToLower(strings.TrimSpace(title))] = i
+		}
+	}
+	if cols["name"] == -1 || cols["value"] == -1 {
+		wb.report(enumName, 0, -1, "", "", fmt.Errorf("invalid enum head,need name and value columns"))
+		return nil, false
+	}
+	enum := &Enum{Name: enumName, index: make(map[string]int64)}
+	valueType := &Type{Kind: TypeScalar, Name: "int32"}
+	//名称、值 -> 所在行
+	names := make(map[string]int)
+	values := make(map[int64]int)
+	ok := true
+	for rowIdx := 1; rowIdx < sheet_root.MaxRow(); rowIdx++ {
+		name, err := cellValue(sheet_root, rowIdx, cols["name"])
+		if err != nil {
+			wb.report(enumName, rowIdx, cols["name"], "name", "", err)
+			ok = false
+			continue
+		}
+		name = strings.TrimSpace(name)
+		value, err := cellValue(sheet_root, rowIdx, cols["value"])
+		if err != nil {
+			wb.report(enumName, rowIdx, cols["value"], "value", "", err)
+			ok = false
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if name == "" && value == "" {
+			continue
+		}
+		if !isIdent(name) {
+			wb.report(enumName, rowIdx, cols["name"], "name", "", fmt.Errorf("invalid enum name %q", name))
+			ok = false
+			continue
+		}
+		if pre, dup := names[name]; dup {
+			wb.report(enumName, rowIdx, cols["name"], "name", "", fmt.Errorf("duplicate enum name %q, first defined at %s", name, CellName(pre, cols["name"])))
+			ok = false
+			continue
+		}
+		names[name] = rowIdx
+		if value == "" {
+			wb.report(enumName, rowIdx, cols["value"], "value", "", fmt.Errorf("empty enum value of %s", name))
+			ok = false
+			continue
+		}
+		v, err := wb.decodeScalar(valueType, value)
+		if err != nil {
+			wb.report(enumName, rowIdx, cols["value"], "value", "", err)
+			ok = false
+			continue
+		}
+		i := v.Scalar.(int64)
+		if pre, dup := values[i]; dup {
+			wb.report(enumName, rowIdx, cols["value"], "value", "", fmt.Errorf("duplicate enum value %d, first defined at %s", i, CellName(pre, cols["value"])))
+			ok = false
+			continue
+		}
+		values[i] = rowIdx
+		desc := ""
+		if cols["desc"] != -1 {
+			if desc, err = cellValue(sheet_root, rowIdx, cols["desc"]); err != nil {
+				wb.report(enumName, rowIdx, cols["desc"], "desc", "", err)
+				ok = false
+				continue
+			}
+		}
+		enum.Values = append(enum.Values, &EnumValue{Name: name, Value: i, Desc: strings.TrimSpace(desc)})
+		enum.index[name] = i
+	}
+	if ok && len(enum.Values) == 0 {
+		wb.report(enumName, -1, -1, "", "", fmt.Errorf("empty enum,no found any value"))
+		return nil, false
+	}
+	return enum, ok
+}
+
+// 根据名称查找枚举值
+func (e *Enum) Lookup(name string) (int64, error) {
+	i, ok := e.index[name]
+	if !ok {
+		return 0, fmt.Errorf("no found value %q in enum %s", name, e.Name)
+	}
+	return i, nil
+}
+
+// 是否为合法的标识符,枚举名称会作为 golang 常量名及 lua 表的 key
+func isIdent(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// 标签及引用的子标签中使用的枚举,按字段顺序
+func (wb *Workbook) usedEnums(sheetNames []string) ([]*Enum, error) {
+	enums := make([]*Enum, 0)
+	seenEnums := make(map[string]bool)
+	parseSheetArray := append([]string(nil), sheetNames...)
+	parsedSheetMap := make(map[string]bool)
+	for _, sheetName := range sheetNames {
+		parsedSheetMap[sheetName] = true
+	}
+	for len(parseSheetArray) > 0 {
+		sheet, err := wb.Sheet(parseSheetArray[0])
+		if err != nil {
+			return nil, err
+		}
+		parseSheetArray = parseSheetArray[1:]
+		for _, field := range sheet.Fields {
+			for _, enumName := range field.Type.enums() {
+				if seenEnums[enumName] {
+					continue
+				}
+				seenEnums[enumName] = true
+				enum, err := wb.Enum(enumName)
+				if err != nil {
+					return nil, err
+				}
+				enums = append(enums, enum)
+			}
+			if leaf := field.Type.Leaf(); leaf.Kind == TypeStruct && !parsedSheetMap[leaf.Name] {
+				parsedSheetMap[leaf.Name] = true
+				parseSheetArray = append(parseSheetArray, leaf.Name)
+			}
+		}
+	}
+	return enums, nil
+}
+
+// 收集所有excel文件导出的标签中使用的枚举,同名枚举只保留一个,输出到 enums.go
+// 未解析的excel文件只解析缓存中记录的枚举标签,不同文件中同名枚举的名称或值不同时记录错误
+func (g *Generator) collectEnums(workbooks map[string]*Workbook, load func(pathfile string) bool) {
+	files := make([]string, 0, len(g.Excels))
+	for pathfile := range g.Excels {
+		files = append(files, pathfile)
+	}
+	sort.Strings(files)
+	defined := make(map[string]*Enum)
+	//枚举名 -> 所在文件
+	owners := make(map[string]string)
+	g.enums = make([]*Enum, 0)
+	for _, pathfile := range files {
+		wb := workbooks[pathfile]
+		enums := make([]*Enum, 0)
+		if load(pathfile) {
+			if wb == nil {
+				continue
+			}
+			//标签中的错误已在解析时记录
+			var err error
+			if enums, err = wb.usedEnums(g.Excels[pathfile]); err != nil {
+				continue
+			}
+		} else if entry, ok := g.cache.Workbooks[pathfile]; ok && len(entry.Enums) > 0 {
+			if wb == nil {
+				var err error
+				if wb, err = g.openWorkbook(pathfile); err != nil {
+					g.diag.Add(&Diagnostic{File: pathfile, Row: -1, Col: -1, Msg: err.Error()})
+					continue
+				}
+				workbooks[pathfile] = wb
+			}
+			for _, enumName := range entry.Enums {
+				enum, err := wb.Enum(enumName)
+				if err != nil {
+					wb.report(enumName, -1, -1, "", "", err)
+					continue
+				}
+				enums = append(enums, enum)
+			}
+		}
+		names := make([]string, 0, len(enums))
+		for _, enum := range enums {
+			names = append(names, enum.Name)
+			if pre, ok := defined[enum.Name]; ok {
+				if !enum.equal(pre) {
+					g.diag.Add(&Diagnostic{File: pathfile, Sheet: enum.Name, Row: -1, Col: -1, Msg: fmt.Sprintf("enum %s is different from the one defined in %s", enum.Name, owners[enum.Name])})
+				}
+				continue
+			}
+			defined[enum.Name] = enum
+			owners[enum.Name] = pathfile
+			g.enums = append(g.enums, enum)
+		}
+		g.enumNames[pathfile] = names
+	}
+	sort.Slice(g.enums, func(i, j int) bool {
+		return g.enums[i].Name < g.enums[j].Name
+	})
+}
+
+// 枚举的名称及值是否相同,忽略顺序及描述
+func (e *Enum) equal(other *Enum) bool {
+	if len(e.index) != len(other.index) {
+		return false
+	}
+	for name, i := range e.index {
+		if j, ok := other.index[name]; !ok || i != j {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 以 csv 目录创建 excel 文件,key=标签名 value=csv 内容
+func writeTestDir(t *testing.T, sheets map[string]string) string {
+	dir, err := ioutil.TempDir("", "xlsx_parser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for sheetName, content := range sheets {
+		if err := ioutil.WriteFile(filepath.Join(dir, sheetName+".csv"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return CleanPath(dir)
+}
+
+func TestCollectEnums(t *testing.T) {
+	quality := "name,value,desc\nCommon,1,普通\nEpic,4,史诗\n"
+	tests := []struct {
+		name  string
+		other string
+		err   string
+	}{
+		//描述及顺序不同视为相同的枚举
+		{"same", "name,value,desc\nEpic,4,Epic\nCommon,1,Common\n", ""},
+		{"different value", "name,value,desc\nCommon,1,普通\nEpic,5,史诗\n", "enum Quality is different from the one defined in"},
+		{"different member", "name,value,desc\nCommon,1,普通\nEpic,4,史诗\nRare,2,稀有\n", "enum Quality is different from the one defined in"},
+	}
+	for _, tt := range tests {
+		a := writeTestDir(t, map[string]string{
+			"Quality": quality,
+			"Item":    "ID,品质\nint32,enum:Quality\nID,Quality\n1,Epic\n",
+		})
+		b := writeTestDir(t, map[string]string{
+			"Quality": tt.other,
+			"Pet":     "ID,品质\nint32,[]enum:Quality\nID,Qualities\n1,\"Common,Epic\"\n",
+		})
+		g := newTestGenerator()
+		g.Excels = map[string][]string{a: {"Item"}, b: {"Pet"}}
+		g.enumNames = make(map[string][]string)
+		g.cache = newBuildCache()
+		all := func(string) bool { return true }
+		workbooks := make(map[string]*Workbook)
+		for pathfile, sheetNames := range g.Excels {
+			workbooks[pathfile] = g.loadWorkbook(nil, pathfile, sheetNames)
+		}
+		g.collectEnums(workbooks, all)
+		if len(g.enums) != 1 || g.enums[0].Name != "Quality" {
+			t.Fatalf("%s: enums = %v", tt.name, g.enums)
+		}
+		if names := g.enumNames[a]; len(names) != 1 || names[0] != "Quality" {
+			t.Errorf("%s: enumNames = %v", tt.name, names)
+		}
+		err := g.diag.Err()
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s: err = %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,1007 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// excel 导出生成器
+type Generator struct {
+	Config
+	//导出过程中收集的错误
+	diag *Diagnostics
+	//配置中指定的导出标签,Excels 为加上自动查找到的标签
+	configured map[string][]string
+	//上次导出的缓存,Force 时为空缓存
+	cache *buildCache
+	//生成器配置的 hash
+	options string
+	//输入文件的 hash key=excel文件路径
+	hashes map[string]string
+	//自动查找时excel文件中表头有效的标签 key=excel文件路径
+	found map[string][]string
+	//外键引用的其他excel文件 key=excel文件路径
+	refs map[string][]string
+	//导出的标签使用的枚举名 key=excel文件路径
+	enumNames map[string][]string
+	//所有excel文件使用的枚举,同名枚举只保留一个,按名称排序
+	enums []*Enum
+}
+
+// 根据配置构建生成器
+func NewGenerator(cfg *Config) (*Generator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	g := &Generator{Config: *cfg, diag: &Diagnostics{}, configured: cfg.Excels}
+	if g.OutGoPath == "" {
+		g.OutGoPath = "./gen_config"
+	}
+	if g.OutLuaPath == "" {
+		g.OutLuaPath = "./lua"
+	}
+	if g.Indent == "" {
+		g.Indent = "\t"
+	}
+	if g.ProtoPackage == "" {
+		g.ProtoPackage = "sample"
+	}
+	if g.CSNamespace == "" {
+		g.CSNamespace = "Sample"
+	}
+	if g.CacheFile == "" {
+		g.CacheFile = filepath.Join(g.OutGoPath, ".xlsx_parser.cache")
+	}
+	return g, nil
+}
+
+// 根据配置导出所有excel文件
+func Generate(ctx context.Context, cfg *Config) error {
+	g, err := NewGenerator(cfg)
+	if err != nil {
+		return err
+	}
+	return g.Generate(ctx)
+}
+
+// golang 源文件输出目录
+func (g *Generator) goDir() string {
+	return filepath.Join(g.OutGoPath, "sample")
+}
+
+// lua 源文件输出目录
+func (g *Generator) luaDir() string {
+	return filepath.Join(g.OutLuaPath, "sample")
+}
+
+// json 文件输出目录
+func (g *Generator) jsonDir() string {
+	return filepath.Join(g.OutJSONPath, "sample")
+}
+
+// msgpack 文件输出目录
+func (g *Generator) msgpackDir() string {
+	return filepath.Join(g.OutMsgpackPath, "sample")
+}
+
+// protobuf 文件输出目录
+func (g *Generator) protoDir() string {
+	return filepath.Join(g.OutProtoPath, "sample")
+}
+
+// C# 源文件输出目录
+func (g *Generator) csDir() string {
+	return filepath.Join(g.OutCSPath, "sample")
+}
+
+// TypeScript 源文件输出目录
+func (g *Generator) tsDir() string {
+	return filepath.Join(g.OutTSPath, "sample")
+}
+
+// 导出所有excel文件
+// 先解析所有excel文件并收集全部错误,存在错误时不输出任何文件,返回 *DiagnosticsError
+// 与缓存记录相比未变化的excel文件跳过解析及输出,Force 时全部重新导出
+func (g *Generator) Generate(ctx context.Context) error {
+	g.diag = &Diagnostics{}
+	g.hashes = make(map[string]string)
+	g.found = make(map[string][]string)
+	g.refs = make(map[string][]string)
+	g.enumNames = make(map[string][]string)
+	g.options = g.optionsHash()
+	if g.Force {
+		g.cache = newBuildCache()
+	} else {
+		g.cache = loadBuildCache(g.CacheFile)
+	}
+	workbooks := g.discover()
+	g.checkClassNames()
+	changed, global := g.changedWorkbooks()
+	if !global {
+		return g.diag.Err()
+	}
+	//.proto 包含所有标签,需要解析未变化的excel文件
+	protoAll := g.OutProtoPath != ""
+	load := func(pathfile string) bool {
+		return changed[pathfile] || protoAll
+	}
+	if err := g.loadWorkbooks(ctx, workbooks, load); err != nil {
+		return err
+	}
+	g.checkRefs(workbooks, load)
+	g.collectEnums(workbooks, load)
+	g.checkTSSheets(workbooks, load)
+	if err := g.diag.Err(); err != nil {
+		return err
+	}
+	wg := &sync.WaitGroup{}
+	errs := &errorList{}
+	//构建模板工厂加载器
+	g.spawn(ctx, wg, errs, func() error {
+		return g.generateGoMapFile()
+	})
+	g.spawn(ctx, wg, errs, func() error {
+		return g.generateGoEnumFile()
+	})
+	g.spawn(ctx, wg, errs, func() error {
+		return g.generateGoLoaderFiles()
+	})
+	g.spawn(ctx, wg, errs, func() error {
+		return g.generateLuaLoaderFiles()
+	})
+	if g.OutProtoPath != "" {
+		g.spawn(ctx, wg, errs, func() error {
+			return g.generateProtoFiles(ctx, workbooks)
+		})
+	}
+	if g.OutCSPath != "" {
+		g.spawn(ctx, wg, errs, func() error {
+			return g.generateCSMapFiles()
+		})
+	}
+	if g.OutTSPath != "" {
+		g.spawn(ctx, wg, errs, func() error {
+			return g.generateTSIndexFile()
+		})
+	}
+	for pathfile, sheetNames := range g.Excels {
+		if !changed[pathfile] {
+			continue
+		}
+		wb := workbooks[pathfile]
+		if goSheets := g.goSheets(sheetNames); len(goSheets) > 0 {
+			g.spawn(ctx, wg, errs, func() error {
+				return g.generateGoFile(ctx, wb, goSheets)
+			})
+			g.spawn(ctx, wg, errs, func() error {
+				return g.generateGoDataFile(ctx, wb, goSheets)
+			})
+		}
+		if luaSheets := g.luaSheets(sheetNames); len(luaSheets) > 0 {
+			g.spawn(ctx, wg, errs, func() error {
+				return g.generateLuaFiles(ctx, wb, luaSheets)
+			})
+		}
+		if jsonSheets := g.jsonSheets(sheetNames); len(jsonSheets) > 0 {
+			g.spawn(ctx, wg, errs, func() error {
+				return g.generateJSONFiles(ctx, wb, jsonSheets)
+			})
+		}
+		if msgpackSheets := g.msgpackSheets(sheetNames); len(msgpackSheets) > 0 {
+			g.spawn(ctx, wg, errs, func() error {
+				return g.generateMsgpackFiles(ctx, wb, msgpackSheets)
+			})
+		}
+		if csSheets := g.csSheets(sheetNames); len(csSheets) > 0 {
+			g.spawn(ctx, wg, errs, func() error {
+				return g.generateCSFile(ctx, wb, csSheets)
+			})
+		}
+		if tsSheets := g.tsSheets(sheetNames); len(tsSheets) > 0 {
+			g.spawn(ctx, wg, errs, func() error {
+				return g.generateTSFiles(ctx, wb, tsSheets)
+			})
+		}
+	}
+	wg.Wait()
+	if err := errs.err(); err != nil {
+		//输出时发现的数据错误已记录到 diag 中(输出前 diag 为空)
+		if derr := g.diag.Err(); derr != nil {
+			return derr
+		}
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	//格式化代码,只格式化本次输出的文件
+	outputs := g.globalOutputs()
+	for pathfile, sheetNames := range g.Excels {
+		if changed[pathfile] {
+			outputs = append(outputs, g.workbookOutputs(pathfile, sheetNames)...)
+		}
+	}
+	args := []string{"-w"}
+	for _, file := range outputs {
+		if filepath.Ext(file) == ".go" {
+			args = append(args, file)
+		}
+	}
+	if len(args) > 1 {
+		if err := exec.Command("gofmt", args...).Run(); err != nil {
+			return fmt.Errorf("go fmt output source file,path:%v ,error:%v", g.goDir(), err)
+		}
+	}
+	return g.saveCache(changed)
+}
+
+// 并发解析 load 返回 true 的excel文件,错误记录到 diag 中
+func (g *Generator) loadWorkbooks(ctx context.Context, workbooks map[string]*Workbook, load func(pathfile string) bool) error {
+	wg := &sync.WaitGroup{}
+	errs := &errorList{}
+	mu := &sync.Mutex{}
+	for pathfile, sheetNames := range g.Excels {
+		if !load(pathfile) {
+			continue
+		}
+		pathfile, sheetNames, wb := pathfile, sheetNames, workbooks[pathfile]
+		g.spawn(ctx, wg, errs, func() error {
+			wb := g.loadWorkbook(wb, pathfile, sheetNames)
+			mu.Lock()
+			defer mu.Unlock()
+			workbooks[pathfile] = wb
+			return nil
+		})
+	}
+	wg.Wait()
+	if err := errs.err(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// 解析excel文件中需要导出的标签及所有数据行,错误记录到 diag 中
+// wb 为空时打开 pathfile
+func (g *Generator) loadWorkbook(wb *Workbook, pathfile string, sheetNames []string) *Workbook {
+	if wb == nil {
+		var err error
+		if wb, err = g.openWorkbook(pathfile); err != nil {
+			g.diag.Add(&Diagnostic{File: pathfile, Row: -1, Col: -1, Msg: err.Error()})
+			return nil
+		}
+	}
+	for _, sheetName := range sheetNames {
+		sheet, err := wb.Sheet(sheetName)
+		if err != nil {
+			wb.report(sheetName, -1, -1, "", "", err)
+			continue
+		}
+		sheet.Records()
+	}
+	return wb
+}
+
+// 并发执行导出任务,任务中的 panic 转换为错误
+func (g *Generator) spawn(ctx context.Context, wg *sync.WaitGroup, errs *errorList, f func() error) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			if e := recover(); e != nil {
+				if err, ok := e.(error); ok {
+					errs.add(err)
+				} else {
+					errs.add(fmt.Errorf("%v", e))
+				}
+			}
+		}()
+		if err := ctx.Err(); err != nil {
+			errs.add(err)
+			return
+		}
+		if err := f(); err != nil {
+			errs.add(err)
+		}
+	}()
+}
+
+// golang 源文件以excel文件名命名,不同目录下的同名文件记录错误
+func (g *Generator) checkClassNames() {
+	files := make([]string, 0, len(g.Excels))
+	for pathfile, sheetNames := range g.Excels {
+		if len(g.goSheets(sheetNames)) > 0 {
+			files = append(files, pathfile)
+		}
+	}
+	sort.Strings(files)
+	owners := make(map[string]string)
+	for _, pathfile := range files {
+		className := strings.ToLower(strings.TrimSuffix(path.Base(pathfile), path.Ext(pathfile)))
+		if owner, ok := owners[className]; ok {
+			g.diag.Add(&Diagnostic{File: pathfile, Row: -1, Col: -1, Msg: fmt.Sprintf("duplicate workbook name, also %s", owner)})
+			continue
+		}
+		owners[className] = pathfile
+	}
+}
+
+// 需要输出 golang 模板工厂的标签
+func (g *Generator) goSheets(sheetNames []string) []string {
+	result := make([]string, 0, len(sheetNames))
+	for _, sheetName := range sheetNames {
+		if !g.SheetOption(sheetName).SkipGo {
+			result = append(result, sheetName)
+		}
+	}
+	return result
+}
+
+// 需要输出 lua 文件的标签
+func (g *Generator) luaSheets(sheetNames []string) []string {
+	result := make([]string, 0, len(sheetNames))
+	for _, sheetName := range sheetNames {
+		if !g.SheetOption(sheetName).SkipLua {
+			result = append(result, sheetName)
+		}
+	}
+	return result
+}
+
+// 需要输出 json 文件的标签,未配置 json 输出目录时不输出
+func (g *Generator) jsonSheets(sheetNames []string) []string {
+	result := make([]string, 0, len(sheetNames))
+	if g.OutJSONPath == "" {
+		return result
+	}
+	for _, sheetName := range sheetNames {
+		if !g.SheetOption(sheetName).SkipJSON {
+			result = append(result, sheetName)
+		}
+	}
+	return result
+}
+
+// 需要输出 msgpack 文件的标签,未配置 msgpack 输出目录时不输出
+func (g *Generator) msgpackSheets(sheetNames []string) []string {
+	result := make([]string, 0, len(sheetNames))
+	if g.OutMsgpackPath == "" {
+		return result
+	}
+	for _, sheetName := range sheetNames {
+		if !g.SheetOption(sheetName).SkipMsgpack {
+			result = append(result, sheetName)
+		}
+	}
+	return result
+}
+
+// 需要输出 C# 类的标签,未配置 C# 输出目录时不输出
+func (g *Generator) csSheets(sheetNames []string) []string {
+	result := make([]string, 0, len(sheetNames))
+	if g.OutCSPath == "" {
+		return result
+	}
+	for _, sheetName := range sheetNames {
+		if !g.SheetOption(sheetName).SkipCS {
+			result = append(result, sheetName)
+		}
+	}
+	return result
+}
+
+// 需要输出 TypeScript 类型及数据的标签,未配置 TypeScript 输出目录时不输出
+func (g *Generator) tsSheets(sheetNames []string) []string {
+	result := make([]string, 0, len(sheetNames))
+	if g.OutTSPath == "" {
+		return result
+	}
+	for _, sheetName := range sheetNames {
+		if !g.SheetOption(sheetName).SkipTS {
+			result = append(result, sheetName)
+		}
+	}
+	return result
+}
+
+func (g *Generator) rootSheets() []string {
+	root_sheets := make([]string, 0)
+	for _, sheetNames := range g.Excels {
+		root_sheets = append(root_sheets, g.goSheets(sheetNames)...)
+	}
+	return root_sheets
+}
+
+func (g *Generator) generateGoMapFile() error {
+	file_path := filepath.Join(g.goDir(), "global_map.go")
+	wcgo, err := openFile(file_path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		wcgo.Close()
+		if e := recover(); e != nil {
+			os.Remove(file_path)
+			panic(e)
+		}
+	}()
+	generateGoMap(func(s string) {
+		if _, err := wcgo.WriteString(s); err != nil {
+			panic(err)
+		}
+	}, g.rootSheets, g.GoData)
+	return nil
+}
+
+// 输出所有excel文件使用的枚举 enums.go,同名枚举只输出一次
+func (g *Generator) generateGoEnumFile() error {
+	return writeOrRemoveFile(filepath.Join(g.goDir(), "enums.go"), true, func(outputf func(s string)) {
+		outputf("//Code generated by xlsx-parser.\n")
+		outputf("//source: github.com/zxfonline/xlsx_parser\n")
+		outputf("//DO NOT EDIT!\n")
+		outputf("\npackage sample\n")
+		if len(g.enums) > 0 {
+			outputf("\nimport \"strconv\"\n")
+		}
+		for _, enum := range g.enums {
+			generateGoEnum(enum, outputf)
+		}
+	})
+}
+
+// 输出 json、msgpack 数据文件的 golang 加载器,未开启的加载器删除之前输出的文件
+// 加载器只加载同时输出了 golang 结构及对应数据文件的标签
+func (g *Generator) generateGoLoaderFiles() error {
+	jsonOn, msgpackOn := g.OutJSONPath != "", g.OutMsgpackPath != ""
+	jsonNames, msgpackNames := make([]string, 0), make([]string, 0)
+	for _, names := range g.Excels {
+		goNames := g.goSheets(names)
+		jsonNames = append(jsonNames, g.jsonSheets(goNames)...)
+		msgpackNames = append(msgpackNames, g.msgpackSheets(goNames)...)
+	}
+	sort.Strings(jsonNames)
+	sort.Strings(msgpackNames)
+	if err := writeOrRemoveFile(filepath.Join(g.goDir(), "loader.go"), jsonOn || msgpackOn, generateGoLoader); err != nil {
+		return err
+	}
+	if err := writeOrRemoveFile(filepath.Join(g.goDir(), "json_loader.go"), jsonOn, func(outputf func(s string)) {
+		generateGoJSONLoader(outputf, jsonNames)
+	}); err != nil {
+		return err
+	}
+	return writeOrRemoveFile(filepath.Join(g.goDir(), "msgpack_loader.go"), msgpackOn, func(outputf func(s string)) {
+		generateGoMsgpackLoader(outputf, msgpackNames)
+	})
+}
+
+// 输出 msgpack 数据文件的 lua 加载器
+func (g *Generator) generateLuaLoaderFiles() error {
+	sheetNames := make([]string, 0)
+	for _, names := range g.Excels {
+		sheetNames = append(sheetNames, g.msgpackSheets(names)...)
+	}
+	sort.Strings(sheetNames)
+	return writeOrRemoveFile(filepath.Join(g.luaDir(), "msgpack_loader.lua"), len(sheetNames) > 0, func(outputf func(s string)) {
+		generateLuaMsgpackLoader(outputf, sheetNames)
+	})
+}
+
+// 输出 C# 模板表实例 global_map.cs 及 json 加载器 json_loader.cs,未输出 json 时删除之前输出的加载器
+func (g *Generator) generateCSMapFiles() error {
+	sheetNames, jsonNames := make([]string, 0), make([]string, 0)
+	for _, names := range g.Excels {
+		csNames := g.csSheets(names)
+		sheetNames = append(sheetNames, csNames...)
+		jsonNames = append(jsonNames, g.jsonSheets(csNames)...)
+	}
+	sort.Strings(sheetNames)
+	sort.Strings(jsonNames)
+	if err := writeOrRemoveFile(filepath.Join(g.csDir(), "global_map.cs"), true, func(outputf func(s string)) {
+		generateCSMap(outputf, g.CSNamespace, sheetNames)
+	}); err != nil {
+		return err
+	}
+	return writeOrRemoveFile(filepath.Join(g.csDir(), "json_loader.cs"), g.OutJSONPath != "", func(outputf func(s string)) {
+		generateCSJSONLoader(outputf, g.CSNamespace, jsonNames)
+	})
+}
+
+// 输出 C# 源文件 file_<文件名>.cs,包含模板表及引用的子标签对应的类
+func (g *Generator) generateCSFile(ctx context.Context, wb *Workbook, sheetNames []string) error {
+	className := path.Base(wb.Path)
+	className = strings.TrimSuffix(className, path.Ext(className))
+	json := g.OutJSONPath != ""
+	return writeOrRemoveFile(filepath.Join(g.csDir(), fmt.Sprintf("file_%s.cs", className)), true, func(printercs func(s string)) {
+		printercs("//Code generated by xlsx-parser.\n")
+		printercs("//source: github.com/zxfonline/xlsx_parser\n")
+		printercs("//DO NOT EDIT!\n\n")
+		printercs("using System.Collections.Generic;\n\n")
+		printercs(fmt.Sprintf("namespace %s\n{", g.CSNamespace))
+		parseSheetArray := append([]string(nil), sheetNames...)
+		parsedSheetMap := make(map[string]bool)
+		for _, sheetName := range sheetNames {
+			sheet, err := wb.Sheet(sheetName)
+			if err != nil {
+				panic(err)
+			}
+			parsedSheetMap[sheet.Name] = true
+			generateCSFactory(sheet, printercs, json)
+		}
+		for len(parseSheetArray) > 0 {
+			if err := ctx.Err(); err != nil {
+				panic(err)
+			}
+			sheet, err := wb.Sheet(parseSheetArray[0])
+			if err != nil {
+				panic(err)
+			}
+			parseSheetArray = parseSheetArray[1:]
+			parseSheetArray = append(parseSheetArray, generateCSFromXLSXFile(sheet, printercs, parsedSheetMap, json)...)
+		}
+		printercs("}\n")
+	})
+}
+
+// 输出 index.ts,导出所有类型及模板数据
+func (g *Generator) generateTSIndexFile() error {
+	modules, sheetNames := make([]string, 0), make([]string, 0)
+	for pathfile, names := range g.Excels {
+		if tsNames := g.tsSheets(names); len(tsNames) > 0 {
+			className := path.Base(pathfile)
+			modules = append(modules, "file_"+strings.TrimSuffix(className, path.Ext(className)))
+			sheetNames = append(sheetNames, tsNames...)
+		}
+	}
+	sort.Strings(modules)
+	sort.Strings(sheetNames)
+	return writeOrRemoveFile(filepath.Join(g.tsDir(), "index.ts"), true, func(outputf func(s string)) {
+		generateTSIndex(outputf, modules, sheetNames)
+	})
+}
+
+// 输出 TypeScript 类型 file_<文件名>.ts 及每个标签的数据 sample_<标签名>.ts
+func (g *Generator) generateTSFiles(ctx context.Context, wb *Workbook, sheetNames []string) error {
+	className := path.Base(wb.Path)
+	module := "file_" + strings.TrimSuffix(className, path.Ext(className))
+	if err := writeOrRemoveFile(filepath.Join(g.tsDir(), module+".ts"), true, func(printerts func(s string)) {
+		printerts("//Code generated by xlsx-parser.\n")
+		printerts("//source: github.com/zxfonline/xlsx_parser\n")
+		printerts("//DO NOT EDIT!\n")
+		parseSheetArray := append([]string(nil), sheetNames...)
+		parsedSheetMap := make(map[string]bool)
+		for _, sheetName := range sheetNames {
+			parsedSheetMap[sheetName] = true
+		}
+		for len(parseSheetArray) > 0 {
+			if err := ctx.Err(); err != nil {
+				panic(err)
+			}
+			sheet, err := wb.Sheet(parseSheetArray[0])
+			if err != nil {
+				panic(err)
+			}
+			parseSheetArray = parseSheetArray[1:]
+			parseSheetArray = append(parseSheetArray, generateTSFromXLSXFile(sheet, printerts, parsedSheetMap)...)
+		}
+	}); err != nil {
+		return err
+	}
+	for _, sheetName := range sheetNames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		sheet, err := wb.Sheet(sheetName)
+		if err != nil {
+			return err
+		}
+		records, err := sheet.Records()
+		if err != nil {
+			return err
+		}
+		if err := writeOrRemoveFile(filepath.Join(g.tsDir(), fmt.Sprintf("sample_%s.ts", sheetName)), true, func(printerts func(s string)) {
+			printerts("//Code generated by xlsx-parser.\n")
+			printerts("//source: github.com/zxfonline/xlsx_parser\n")
+			printerts("//DO NOT EDIT!\n\n")
+			g.generateTSData(sheet, records, printerts, module)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Generator) generateGoFile(ctx context.Context, wb *Workbook, sheetNames []string) error {
+	className := path.Base(wb.Path)
+	className = strings.TrimSuffix(className, path.Ext(className))
+	sheets := make([]*Sheet, 0, len(sheetNames))
+	for _, sheetName := range sheetNames {
+		sheet, err := wb.Sheet(sheetName)
+		if err != nil {
+			return err
+		}
+		sheets = append(sheets, sheet)
+	}
+	file_path := filepath.Join(g.goDir(), fmt.Sprintf("file_%s.go", className))
+	wcgo, err := openFile(file_path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		wcgo.Close()
+		if e := recover(); e != nil {
+			os.Remove(file_path)
+			panic(e)
+		}
+	}()
+	printergo := func(s string) {
+		if _, err := wcgo.WriteString(s); err != nil {
+			panic(err)
+		}
+	}
+	printergo("//Code generated by xlsx-parser.\n")
+	printergo("//source: github.com/zxfonline/xlsx_parser\n")
+	printergo("//DO NOT EDIT!\n")
+	//输出包头
+	printergo("\npackage sample\n\n")
+	if goIndexSort(sheets) {
+		printergo("import \"sort\"\n\n")
+	}
+	//待解析的标签队列
+	parseSheetArray := make([]string, 0, len(sheetNames))
+	parseSheetArray = append(parseSheetArray, sheetNames...)
+	//加入过解析队列的excel标签
+	parsedSheetMap := make(map[string]bool)
+	for _, sheet := range sheets {
+		parsedSheetMap[sheet.Name] = true
+		//输出模板工厂
+		generateGoFactory(sheet, printergo)
+		generateGoIndex(sheet, printergo)
+	}
+	//开始输出结构体
+	for len(parseSheetArray) > 0 {
+		if err := ctx.Err(); err != nil {
+			panic(err)
+		}
+		sheet, err := wb.Sheet(parseSheetArray[0])
+		if err != nil {
+			panic(err)
+		}
+		parseSheetArray = parseSheetArray[1:]
+		addParseSheetArray := generateGoFromXLSXFile(sheet, printergo, parsedSheetMap)
+		parseSheetArray = append(parseSheetArray, addParseSheetArray...)
+	}
+	return nil
+}
+
+// 输出标签数据 data_<文件名>.go,未开启 GoData 时删除之前输出的数据文件
+func (g *Generator) generateGoDataFile(ctx context.Context, wb *Workbook, sheetNames []string) error {
+	className := path.Base(wb.Path)
+	className = strings.TrimSuffix(className, path.Ext(className))
+	file_path := filepath.Join(g.goDir(), fmt.Sprintf("data_%s.go", className))
+	if !g.GoData {
+		if err := os.Remove(file_path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	wcgo, err := openFile(file_path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		wcgo.Close()
+		if e := recover(); e != nil {
+			os.Remove(file_path)
+			panic(e)
+		}
+	}()
+	printergo := func(s string) {
+		if _, err := wcgo.WriteString(s); err != nil {
+			panic(err)
+		}
+	}
+	printergo("//Code generated by xlsx-parser.\n")
+	printergo("//source: github.com/zxfonline/xlsx_parser\n")
+	printergo("//DO NOT EDIT!\n")
+	printergo("\npackage sample\n")
+	for _, sheetName := range sheetNames {
+		if err := ctx.Err(); err != nil {
+			panic(err)
+		}
+		sheet, err := wb.Sheet(sheetName)
+		if err != nil {
+			panic(err)
+		}
+		records, err := sheet.Records()
+		if err != nil {
+			panic(err)
+		}
+		generateGoData(sheet, records, printergo)
+	}
+	return nil
+}
+
+func (g *Generator) generateLuaFiles(ctx context.Context, wb *Workbook, sheetNames []string) error {
+	if err := g.generateLuaAnnotationFile(ctx, wb, sheetNames); err != nil {
+		return err
+	}
+	for _, sheetName := range sheetNames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := g.generateLuaFile(wb, sheetName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 输出标签及引用的子标签的 EmmyLua/LuaLS 类型注解及引用的枚举表 file_<文件名>.lua
+func (g *Generator) generateLuaAnnotationFile(ctx context.Context, wb *Workbook, sheetNames []string) error {
+	className := path.Base(wb.Path)
+	className = strings.TrimSuffix(className, path.Ext(className))
+	enums, err := wb.usedEnums(sheetNames)
+	if err != nil {
+		return err
+	}
+	return writeOrRemoveFile(filepath.Join(g.luaDir(), fmt.Sprintf("file_%s.lua", className)), true, func(printerlua func(s string)) {
+		printerlua("--[[\nCode generated by xlsx-parser.\n")
+		printerlua("source: github.com/zxfonline/xlsx_parser\n")
+		printerlua("DO NOT EDIT!\n]]\n")
+		parseSheetArray := append([]string(nil), sheetNames...)
+		parsedSheetMap := make(map[string]bool)
+		for _, sheetName := range sheetNames {
+			parsedSheetMap[sheetName] = true
+		}
+		for len(parseSheetArray) > 0 {
+			if err := ctx.Err(); err != nil {
+				panic(err)
+			}
+			sheet, err := wb.Sheet(parseSheetArray[0])
+			if err != nil {
+				panic(err)
+			}
+			parseSheetArray = parseSheetArray[1:]
+			parseSheetArray = append(parseSheetArray, generateLuaAnnotationFromXLSXFile(sheet, printerlua, parsedSheetMap)...)
+		}
+		for _, enum := range enums {
+			g.generateLuaEnum(enum, printerlua)
+		}
+	})
+}
+
+func (g *Generator) generateLuaFile(wb *Workbook, sheetName string) error {
+	sheet, err := wb.Sheet(sheetName)
+	if err != nil {
+		return err
+	}
+	records, err := sheet.Records()
+	if err != nil {
+		return err
+	}
+	file_path := filepath.Join(g.luaDir(), fmt.Sprintf("sample_%s.lua", sheetName))
+	wclua, err := openFile(file_path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		wclua.Close()
+		if e := recover(); e != nil {
+			os.Remove(file_path)
+			panic(e)
+		}
+	}()
+	printerlua := func(s string) {
+		if _, err := wclua.WriteString(s); err != nil {
+			panic(err)
+		}
+	}
+	printerlua("--[[\nCode generated by xlsx-parser.\n")
+	printerlua("source: github.com/zxfonline/xlsx_parser\n")
+	printerlua("DO NOT EDIT!\n=====attr desc========")
+	if err := g.generateLuaDescFromXLSXFile(wb, sheetName, printerlua, g.Indent); err != nil {
+		panic(err)
+	}
+	printerlua("\n]]\n")
+	printerlua(fmt.Sprintf("\n---@type %s\nS_%s={", luaSheetType(sheet), sheetName))
+	g.generateLuaContentFromXLSXFile(records, printerlua)
+	printerlua("\n}\n")
+	g.generateLuaIndex(sheet, records, printerlua)
+	return nil
+}
+
+func (g *Generator) generateJSONFiles(ctx context.Context, wb *Workbook, sheetNames []string) error {
+	for _, sheetName := range sheetNames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := g.generateJSONFile(wb, sheetName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Generator) generateJSONFile(wb *Workbook, sheetName string) error {
+	sheet, err := wb.Sheet(sheetName)
+	if err != nil {
+		return err
+	}
+	records, err := sheet.Records()
+	if err != nil {
+		return err
+	}
+	file_path := filepath.Join(g.jsonDir(), fmt.Sprintf("sample_%s.json", sheetName))
+	wcjson, err := openFile(file_path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		wcjson.Close()
+		if e := recover(); e != nil {
+			os.Remove(file_path)
+			panic(e)
+		}
+	}()
+	return g.generateJSONContent(records, func(s string) {
+		if _, err := wcjson.WriteString(s); err != nil {
+			panic(err)
+		}
+	})
+}
+
+func (g *Generator) generateMsgpackFiles(ctx context.Context, wb *Workbook, sheetNames []string) error {
+	for _, sheetName := range sheetNames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		sheet, err := wb.Sheet(sheetName)
+		if err != nil {
+			return err
+		}
+		records, err := sheet.Records()
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		generateMsgpackContent(records, &buf)
+		file_path := filepath.Join(g.msgpackDir(), fmt.Sprintf("sample_%s.msgpack", sheetName))
+		if err := writeOrRemoveFile(file_path, true, func(outputf func(s string)) {
+			outputf(buf.String())
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 输出 sample.proto 及所有导出标签的数据文件 sample_<标签名>.pb
+// 字段编号记录在 sample.proto.lock 中,调整列顺序不影响编号
+func (g *Generator) generateProtoFiles(ctx context.Context, workbooks map[string]*Workbook) error {
+	lock_path := filepath.Join(g.protoDir(), "sample.proto.lock")
+	lock, err := loadProtoLock(lock_path)
+	if err != nil {
+		return err
+	}
+	schema := newProtoSchema(lock)
+	files := make([]string, 0, len(g.Excels))
+	for pathfile := range g.Excels {
+		files = append(files, pathfile)
+	}
+	sort.Strings(files)
+	sheets := make([]*Sheet, 0)
+	for _, pathfile := range files {
+		for _, sheetName := range g.Excels[pathfile] {
+			sheet, err := workbooks[pathfile].Sheet(sheetName)
+			if err != nil {
+				return err
+			}
+			if err := schema.addSheet(sheet); err != nil {
+				return err
+			}
+			schema.addContainer(sheet)
+			sheets = append(sheets, sheet)
+		}
+	}
+	for _, sheet := range sheets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		records, err := sheet.Records()
+		if err != nil {
+			return err
+		}
+		data := schema.encodeRecords(records)
+		file_path := filepath.Join(g.protoDir(), fmt.Sprintf("sample_%s.pb", sheet.Name))
+		if err := writeOrRemoveFile(file_path, true, func(outputf func(s string)) {
+			outputf(string(data))
+		}); err != nil {
+			return err
+		}
+	}
+	if err := writeOrRemoveFile(filepath.Join(g.protoDir(), "sample.proto"), true, func(outputf func(s string)) {
+		schema.generate(outputf, g.ProtoPackage)
+	}); err != nil {
+		return err
+	}
+	return lock.save(lock_path)
+}
+
+// 并发任务错误收集
+type errorList struct {
+	sync.Mutex
+	errs []error
+}
+
+func (l *errorList) add(err error) {
+	l.Lock()
+	defer l.Unlock()
+	l.errs = append(l.errs, err)
+}
+
+func (l *errorList) err() error {
+	l.Lock()
+	defer l.Unlock()
+	if len(l.errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(l.errs))
+	for _, err := range l.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "\n"))
+}
+
+// write 为 true 时输出文件,否则删除之前输出的文件
+func writeOrRemoveFile(pathfile string, write bool, generate func(outputf func(s string))) error {
+	if !write {
+		if err := os.Remove(pathfile); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	wc, err := openFile(pathfile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		wc.Close()
+		if e := recover(); e != nil {
+			os.Remove(pathfile)
+			panic(e)
+		}
+	}()
+	generate(func(s string) {
+		if _, err := wc.WriteString(s); err != nil {
+			panic(err)
+		}
+	})
+	return nil
+}
+
+func openFile(pathfile string) (wc *os.File, err error) {
+	dir, _ := filepath.Split(pathfile)
+	if _, err = os.Stat(dir); err != nil && !os.IsExist(err) {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+			return nil, err
+		}
+		if _, err = os.Stat(dir); err != nil {
+			return nil, err
+		}
+	}
+	return os.OpenFile(pathfile, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, os.ModePerm)
+}
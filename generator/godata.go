@@ -0,0 +1,126 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 输出标签数据,init 时通过 DynamicUpdateSampleFactory 注册 SF_<标签名>
+func generateGoData(sheet *Sheet, records []*Record, outputf func(s string)) {
+	outputf(fmt.Sprintf("\nvar _ = registSampleData(\"SF_%s\", func() SampleFactory {\n", sheet.Name))
+	outputf(fmt.Sprintf("\treturn SF_%s{\n", sheet.Name))
+	for _, record := range records {
+		outputf(fmt.Sprintf("\t\t%s: &%s,\n", goKey(record), goRecord(record)))
+	}
+	outputf("\t}\n})\n")
+}
+
+// 主键转换为 golang 表达式,组合主键为 SK_<标签名>{...}
+func goKey(record *Record) string {
+	if !record.Sheet.Composite() {
+		return goValue(record.Key())
+	}
+	keys := make([]string, 0, len(record.Sheet.Keys))
+	for _, key := range record.Sheet.Keys {
+		keys = append(keys, fmt.Sprintf("P_%s: %s", key.Name, goValue(record.Values[key.Index])))
+	}
+	return fmt.Sprintf("SK_%s{%s}", record.Sheet.Name, strings.Join(keys, ", "))
+}
+
+// 数据行转换为结构体字面量,零值字段及未设置的可选字段省略
+func goRecord(record *Record) string {
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("S_%s{", record.Sheet.Name))
+	first := true
+	for i, field := range record.Sheet.Fields {
+		v := record.Values[i]
+		if v == nil || (!field.Optional() && v.IsZero()) {
+			continue
+		}
+		if !first {
+			buf.WriteString(", ")
+		}
+		first = false
+		buf.WriteString(fmt.Sprintf("P_%s: %s", field.Name, goFieldValue(field, v)))
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// 数据转换为 golang 表达式
+func goValue(v *Value) string {
+	return goLiteral(v, true)
+}
+
+// 字段数据转换为 golang 表达式,可选字段为指针 eg: &[]int32{5}[0]  &S_Item{...}
+func goFieldValue(field *Field, v *Value) string {
+	if !field.Optional() {
+		return goValue(v)
+	}
+	switch v.Type.Kind {
+	case TypeScalar:
+		return fmt.Sprintf("&[]%s{%s}[0]", v.Type.GoType(), goValue(v))
+	case TypeStruct:
+		return "&" + goValue(v)
+	}
+	return goValue(v)
+}
+
+// typed 为 false 时省略复合字面量的类型(数组元素、map 的 key 和值)
+func goLiteral(v *Value, typed bool) string {
+	switch v.Type.Kind {
+	case TypeScalar:
+		switch s := v.Scalar.(type) {
+		case string:
+			return strconv.Quote(s)
+		default:
+			return v.String()
+		}
+	case TypeStruct:
+		if typed {
+			return goRecord(v.Record)
+		}
+		return strings.TrimPrefix(goRecord(v.Record), "S_"+v.Type.Name)
+	}
+	var buf strings.Builder
+	if typed {
+		buf.WriteString(v.Type.GoType())
+	}
+	buf.WriteString("{")
+	for i, elem := range v.List {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if v.Type.Kind == TypeMap {
+			buf.WriteString(goLiteral(v.Keys[i], false) + ": ")
+		}
+		buf.WriteString(goLiteral(elem, false))
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// 是否为零值: 基础数据类型的零值、空数组、空 map
+func (v *Value) IsZero() bool {
+	switch v.Type.Kind {
+	case TypeScalar:
+		switch s := v.Scalar.(type) {
+		case int64:
+			return s == 0
+		case float64:
+			return s == 0
+		case string:
+			return s == ""
+		case bool:
+			return !s
+		}
+	case TypeSlice, TypeMap:
+		return len(v.List) == 0
+	}
+	return false
+}
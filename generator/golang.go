@@ -0,0 +1,392 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// 组合主键输出主键结构体 SK_<标签名> 及按主键获取的 GetByKey
+func generateGoFactory(sheet *Sheet, outputf func(s string)) {
+	tmpl := template.Must(template.New("codeBaseTemplate").Parse(`
+{{- if .Composite}}
+	//组合主键
+	type SK_{{.Name}} struct {
+	{{- range .Keys}}
+		P_{{.Name}} {{.Type.GoType}}
+	{{- end}}
+	}
+{{end}}
+	type SF_{{.Name}} map[{{.KeyType}}]*S_{{.Name}}
+
+	//获取模板数据(请勿在模板数据上修改数据) sid 数据类型={{.KeyType}}
+	func (f SF_{{.Name}}) Get(sid interface{}) Sample {
+		if s, pre := f[sid.({{.KeyType}})]; pre {
+			return s
+		}
+		return nil
+	}
+{{- if .Composite}}
+
+	//按组合主键获取模板数据(请勿在模板数据上修改数据)
+	func (f SF_{{.Name}}) GetByKey({{range $i, $k := .Keys}}{{if $i}}, {{end}}P_{{$k.Name}} {{$k.Type.GoType}}{{end}}) *S_{{.Name}} {
+		return f[SK_{{.Name}}{ {{- range $i, $k := .Keys}}{{if $i}}, {{end}}P_{{$k.Name}}{{end -}} }]
+	}
+	// sid 数据类型={{.KeyType}}
+	func (s *S_{{.Name}}) Sid() interface{} {
+		return SK_{{.Name}}{ {{- range $i, $k := .Keys}}{{if $i}}, {{end}}s.P_{{$k.Name}}{{end -}} }
+	}
+{{- else}}
+	// sid 数据类型={{.KeyType}}
+	func (s *S_{{.Name}}) Sid() interface{} {
+		return s.P_{{.Key.Name}}
+	}
+{{- end}}
+	`))
+	keyType := sheet.Key.Type.GoType()
+	if sheet.Composite() {
+		keyType = "SK_" + sheet.Name
+	}
+	var bs bytes.Buffer
+	if err := tmpl.Execute(&bs, struct {
+		Name      string
+		Key       *Field
+		Keys      []*Field
+		KeyType   string
+		Composite bool
+	}{sheet.Name, sheet.Key, sheet.Keys, keyType, sheet.Composite()}); err != nil {
+		panic(err)
+	}
+	outputf(fmt.Sprintf("%s\n", bs.String()))
+}
+
+// 输出 index 字段的 GetSF_<标签名>By<字段名> 及 group 字段的 ListSF_<标签名>By<字段名>
+// 索引在 DynamicUpdateSampleFactory 注册模板数据时建立,对应已注册的模板数据,group 的数据按主键排序
+func generateGoIndex(sheet *Sheet, outputf func(s string)) {
+	type goIndex struct {
+		Name  string
+		Type  string
+		Group bool
+	}
+	indexes := make([]goIndex, 0)
+	for _, field := range sheet.Indexes() {
+		indexes = append(indexes, goIndex{field.Name, field.Type.GoType(), field.Constraints.Group})
+	}
+	if len(indexes) == 0 {
+		return
+	}
+	tmpl := template.Must(template.New("codeGoIndexTemplate").Parse(`
+	//SF_{{.Name}} 的索引,注册模板数据时建立
+	var _SI_{{.Name}} struct {
+	{{- range .Indexes}}
+		by{{.Name}} map[{{.Type}}]{{if .Group}}[]{{end}}*S_{{$.Name}}
+	{{- end}}
+	}
+
+	var _ = registSampleIndex("SF_{{.Name}}", func(sf SampleFactory) {
+		switch f := sf.(type) {
+		case SF_{{.Name}}:
+			buildSF_{{.Name}}Index(f)
+		case *SF_{{.Name}}:
+			buildSF_{{.Name}}Index(*f)
+		}
+	})
+
+	//建立索引,由 DynamicUpdateSampleFactory 调用
+	func buildSF_{{.Name}}Index(f SF_{{.Name}}) {
+	{{- range .Indexes}}
+		_SI_{{$.Name}}.by{{.Name}} = make(map[{{.Type}}]{{if .Group}}[]{{end}}*S_{{$.Name}})
+	{{- end}}
+		for _, s := range f {
+		{{- range .Indexes}}
+		{{- if .Group}}
+			_SI_{{$.Name}}.by{{.Name}}[s.P_{{.Name}}] = append(_SI_{{$.Name}}.by{{.Name}}[s.P_{{.Name}}], s)
+		{{- else}}
+			_SI_{{$.Name}}.by{{.Name}}[s.P_{{.Name}}] = s
+		{{- end}}
+		{{- end}}
+		}
+	{{- range .Indexes}}{{if .Group}}
+		for _, list := range _SI_{{$.Name}}.by{{.Name}} {
+			sort.Slice(list, func(i, j int) bool { return {{$.Less}} })
+		}
+	{{- end}}{{end}}
+	}
+{{range .Indexes}}
+{{- if .Group}}
+	//按 {{.Name}} 获取已注册的 SF_{{$.Name}} 模板数据列表,按主键排序(请勿在模板数据上修改数据)
+	func ListSF_{{$.Name}}By{{.Name}}(P_{{.Name}} {{.Type}}) []*S_{{$.Name}} {
+{{- else}}
+	//按 {{.Name}} 获取已注册的 SF_{{$.Name}} 模板数据(请勿在模板数据上修改数据)
+	func GetSF_{{$.Name}}By{{.Name}}(P_{{.Name}} {{.Type}}) *S_{{$.Name}} {
+{{- end}}
+		mapLock.RLock()
+		defer mapLock.RUnlock()
+		return _SI_{{$.Name}}.by{{.Name}}[P_{{.Name}}]
+	}
+{{end}}`))
+	var bs bytes.Buffer
+	if err := tmpl.Execute(&bs, struct {
+		Name    string
+		Indexes []goIndex
+		Less    string
+	}{sheet.Name, indexes, goKeyLess(sheet, "list[i]", "list[j]")}); err != nil {
+		panic(err)
+	}
+	outputf(bs.String())
+}
+
+// 是否输出了 group 字段的索引,需要引入 sort
+func goIndexSort(sheets []*Sheet) bool {
+	for _, sheet := range sheets {
+		for _, field := range sheet.Indexes() {
+			if field.Constraints.Group {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 按主键比较 a、b 的表达式,组合主键依次比较
+func goKeyLess(sheet *Sheet, a, b string) string {
+	less := ""
+	equal := ""
+	for _, key := range sheet.Keys {
+		x, y := fmt.Sprintf("%s.P_%s", a, key.Name), fmt.Sprintf("%s.P_%s", b, key.Name)
+		term := fmt.Sprintf("%s < %s", x, y)
+		if key.Type.Name == "bool" {
+			term = fmt.Sprintf("!%s && %s", x, y)
+		}
+		if less != "" {
+			less += " || "
+		}
+		less += equal + term
+		equal += fmt.Sprintf("%s == %s && ", x, y)
+	}
+	return less
+}
+
+// 输出标签对应的结构体,返回需要继续输出的子标签
+func generateGoFromXLSXFile(sheet *Sheet, outputf func(s string), parsedSheetMap map[string]bool) (addParseSheetArray []string) {
+	outputf(fmt.Sprintf("type S_%s struct {\n", sheet.Name))
+	for _, field := range sheet.Fields {
+		outputf("\t/*")
+		outputf(field.Desc)
+		outputf("*/\n")
+		outputf(fmt.Sprintf("\tP_%s %s\n", field.Name, goFieldType(field)))
+		if leaf := field.Type.Leaf(); leaf.Kind == TypeStruct && !parsedSheetMap[leaf.Name] {
+			parsedSheetMap[leaf.Name] = true
+			addParseSheetArray = append(addParseSheetArray, leaf.Name)
+		}
+	}
+	outputf("}\n")
+	return
+}
+
+// 输出枚举类型、常量及 String 方法,常量名为 枚举名_名称,需要引入 strconv
+func generateGoEnum(enum *Enum, outputf func(s string)) {
+	tmpl := template.Must(template.New("codeGoEnumTemplate").Parse(`
+type {{.Name}} int32
+
+const (
+{{- range .Values}}
+	/*{{.Desc}}*/
+	{{$.Name}}_{{.Name}} {{$.Name}} = {{.Value}}
+{{- end}}
+)
+
+func (e {{.Name}}) String() string {
+	switch e {
+{{- range .Values}}
+	case {{$.Name}}_{{.Name}}:
+		return "{{.Name}}"
+{{- end}}
+	}
+	return "{{.Name}}(" + strconv.FormatInt(int64(e), 10) + ")"
+}
+`))
+	var bs bytes.Buffer
+	if err := tmpl.Execute(&bs, enum); err != nil {
+		panic(err)
+	}
+	outputf(bs.String())
+}
+
+// 字段的 golang 数据类型,可选的基础数据类型及结构体为指针,nil 表示未设置
+func goFieldType(field *Field) string {
+	if field.Optional() && (field.Type.Kind == TypeScalar || field.Type.Kind == TypeStruct) {
+		return "*" + field.Type.GoType()
+	}
+	return field.Type.GoType()
+}
+
+// data 为 true 时输出加载预生成模板数据的代码
+func generateGoMap(outputf func(s string), Factory func() []string, data bool) {
+	tmpl := template.Must(template.New("codeGoMapTemplate").Parse(`
+//Code generated by xlsx-parser.
+//source: github.com/zxfonline/xlsx_parser
+//DO NOT EDIT!
+
+package sample
+
+import (
+	"reflect"
+
+	"sync"
+
+	"github.com/zxfonline/golog"
+)
+
+var (
+	_FACTORY_BUILDERS map[SampleKey]*sampleFactoryBuilder
+	_GLOBAL_MAP       map[SampleKey]SampleFactory
+
+	_GLOBAL_NAMEKEY map[string]SampleKey
+
+	log = golog.New("SampleFactory")
+
+	mapLock     sync.RWMutex
+	builderLock sync.RWMutex
+)
+
+type SampleKey int
+
+const (
+	SampleKey_Begin = SampleKey(0) + iota
+	{{range .Sheets}}SampleKey_SF_{{.}}{{"\n"}}{{end}}
+)
+
+func init() {
+	_FACTORY_BUILDERS = make(map[SampleKey]*sampleFactoryBuilder)
+	_GLOBAL_MAP = make(map[SampleKey]SampleFactory)
+	_GLOBAL_NAMEKEY = make(map[string]SampleKey)
+	//初始化模板名对应的模板key
+	{{range .Sheets}}_GLOBAL_NAMEKEY["SF_{{.}}"] = SampleKey_SF_{{.}}{{"\n"}}{{end}}
+	//配置模板注册
+	{{range .Sheets}}RegistSampleFactoryBuilder(&SF_{{.}}{}){{"\n"}}{{end}}
+	{{- if .Data}}
+	//加载预生成的模板数据
+	for name, data := range _SAMPLE_DATAS {
+		DynamicUpdateSampleFactory(name, data())
+	}
+	{{- end}}
+}
+
+//建立 index、group 字段索引的函数 key=模板名
+var _SAMPLE_INDEXES map[string]func(sf SampleFactory)
+
+//注册建立索引的函数,在 DynamicUpdateSampleFactory 中调用
+func registSampleIndex(name string, build func(sf SampleFactory)) bool {
+	if _SAMPLE_INDEXES == nil {
+		_SAMPLE_INDEXES = make(map[string]func(sf SampleFactory))
+	}
+	_SAMPLE_INDEXES[name] = build
+	return true
+}
+{{if .Data}}
+//预生成的模板数据 key=模板名
+var _SAMPLE_DATAS map[string]func() SampleFactory
+
+//注册预生成的模板数据,在 init 中加载
+func registSampleData(name string, data func() SampleFactory) bool {
+	if _SAMPLE_DATAS == nil {
+		_SAMPLE_DATAS = make(map[string]func() SampleFactory)
+	}
+	_SAMPLE_DATAS[name] = data
+	return true
+}
+{{end}}
+
+type sampleFactoryBuilder struct {
+	typeOf reflect.Type
+}
+
+// only support pointer of a struct or a struct
+// &ta{} -> ta,ta{} -> ta
+// for debug use fmt.Printf("%T",xxx)
+func RegistSampleFactoryBuilder(factory SampleFactory) {
+	builderLock.Lock()
+	defer builderLock.Unlock()
+	typof := IndirectType(reflect.TypeOf(factory))
+	name := typof.Name()
+	mk := _GLOBAL_NAMEKEY[name]
+	//注册handler
+	if _, pre := _FACTORY_BUILDERS[mk]; pre {
+		log.Infof("Replace sample builder \"%s\"", name)
+	} else {
+		log.Infof("Add sample builder \"%s\"", name)
+	}
+	_FACTORY_BUILDERS[mk] = &sampleFactoryBuilder{typeOf: typof}
+}
+
+func IndirectType(v reflect.Type) reflect.Type {
+	switch v.Kind() {
+	case reflect.Ptr:
+		return IndirectType(v.Elem())
+	default:
+		return v
+	}
+	return v
+}
+
+func InstanceSFBuilder(name string) SampleFactory {
+	builderLock.RLock()
+	defer builderLock.RUnlock()
+	if sample, pre := _FACTORY_BUILDERS[_GLOBAL_NAMEKEY[name]]; pre {
+		return reflect.New(sample.typeOf).Interface().(SampleFactory)
+	} else {
+		return nil
+	}
+}
+
+func GetSampleFactory(name SampleKey) SampleFactory {
+	mapLock.RLock()
+	defer mapLock.RUnlock()
+	if sf, pre := _GLOBAL_MAP[name]; pre {
+		return sf
+	} else {
+		return nil
+	}
+}
+
+func DynamicUpdateSampleFactory(name string, sf SampleFactory) {
+	mapLock.Lock()
+	defer mapLock.Unlock()
+	mk := _GLOBAL_NAMEKEY[name]
+	if _, pre := _GLOBAL_MAP[mk]; pre {
+		log.Infof("Replace sample factory \"%s\"", name)
+	} else {
+		log.Infof("Add sample factory \"%s\"", name)
+	}
+	_GLOBAL_MAP[mk] = sf
+	//建立 index、group 字段的索引
+	if build, ok := _SAMPLE_INDEXES[name]; ok {
+		build(sf)
+	}
+}
+
+//模板接口
+type Sample interface {
+	Sid() interface{}
+}
+
+type SampleFactory interface {
+	Get(sid interface{}) Sample
+}
+
+	`))
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, struct {
+		Sheets []string
+		Data   bool
+	}{Factory(), data})
+	if err != nil {
+		panic(err)
+	}
+	outputf(buf.String())
+}
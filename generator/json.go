@@ -0,0 +1,141 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// 输出标签所有数据 {"主键":{...}},与 lua 相同,结构体展开为子对象,组合主键逐层嵌套
+func (g *Generator) generateJSONContent(records []*Record, outputf func(s string)) error {
+	var buf bytes.Buffer
+	jsonKeyNodes(&buf, keyTree(records, 0))
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", g.Indent); err != nil {
+		return err
+	}
+	outputf(out.String())
+	outputf("\n")
+	return nil
+}
+
+func jsonKeyNodes(buf *bytes.Buffer, nodes []*keyNode) {
+	buf.WriteString("{")
+	for i, node := range nodes {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(jsonQuote(node.Key.String()) + ":")
+		if node.Record != nil {
+			jsonRecord(buf, node.Record)
+		} else {
+			jsonKeyNodes(buf, node.Children)
+		}
+	}
+	buf.WriteString("}")
+}
+
+// 一行数据输出为对象,字段名与 lua 相同为 P_字段名,未设置的可选字段省略
+func jsonRecord(buf *bytes.Buffer, record *Record) {
+	buf.WriteString("{")
+	first := true
+	for i, field := range record.Sheet.Fields {
+		if record.Values[i] == nil {
+			continue
+		}
+		if !first {
+			buf.WriteString(",")
+		}
+		first = false
+		buf.WriteString(jsonQuote("P_"+field.Name) + ":")
+		jsonValue(buf, record.Values[i])
+	}
+	buf.WriteString("}")
+}
+
+// 数据转换为 json,map 的 key 统一为字符串
+func jsonValue(buf *bytes.Buffer, v *Value) {
+	switch v.Type.Kind {
+	case TypeScalar:
+		switch s := v.Scalar.(type) {
+		case string:
+			buf.WriteString(jsonQuote(s))
+		default:
+			buf.WriteString(v.String())
+		}
+	case TypeStruct:
+		jsonRecord(buf, v.Record)
+	case TypeSlice:
+		buf.WriteString("[")
+		for i, elem := range v.List {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			jsonValue(buf, elem)
+		}
+		buf.WriteString("]")
+	case TypeMap:
+		buf.WriteString("{")
+		for i, elem := range v.List {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString(jsonQuote(v.Keys[i].String()) + ":")
+			jsonValue(buf, elem)
+		}
+		buf.WriteString("}")
+	}
+}
+
+func jsonQuote(s string) string {
+	bs, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	return string(bs)
+}
+
+// 输出 LoadJSON(dir),从 json 文件加载 sheetNames 对应的模板数据
+func generateGoJSONLoader(outputf func(s string), sheetNames []string) {
+	outputf(strings.TrimLeft(`
+//Code generated by xlsx-parser.
+//source: github.com/zxfonline/xlsx_parser
+//DO NOT EDIT!
+
+package sample
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+)
+
+//从 dir 目录下的 sample_<标签名>.json 加载所有导出了 json 的模板数据
+func LoadJSON(dir string) error {
+	for _, name := range jsonSampleNames {
+		pathfile := filepath.Join(dir, sampleFileName(name, ".json"))
+		data, err := ioutil.ReadFile(pathfile)
+		if err != nil {
+			return err
+		}
+		var raw interface{}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("load %s,err:%v", pathfile, err)
+		}
+		if err := loadSample(name, raw); err != nil {
+			return fmt.Errorf("load %s,err:%v", pathfile, err)
+		}
+	}
+	return nil
+}
+`, "\n"))
+	generateGoSampleNames(outputf, "jsonSampleNames", "导出了 json 数据文件的模板名", sheetNames)
+}
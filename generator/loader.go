@@ -0,0 +1,165 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 输出 json、msgpack 等数据文件加载器共用的代码
+// 数据文件与 lua 结构相同: {"主键":{"P_字段名":值}},map 的 key 为字符串
+func generateGoLoader(outputf func(s string)) {
+	outputf(strings.TrimLeft(`
+//Code generated by xlsx-parser.
+//source: github.com/zxfonline/xlsx_parser
+//DO NOT EDIT!
+
+package sample
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//模板名对应的数据文件名 SF_X -> sample_X.ext
+func sampleFileName(name, ext string) string {
+	return "sample_" + strings.TrimPrefix(name, "SF_") + ext
+}
+
+//解码后的数据转换为模板数据并注册
+func loadSample(name string, raw interface{}) error {
+	sf := InstanceSFBuilder(name)
+	if sf == nil {
+		return fmt.Errorf("no found sample builder %s", name)
+	}
+	v := reflect.ValueOf(sf).Elem()
+	if err := sampleAssign(v, raw); err != nil {
+		return err
+	}
+	DynamicUpdateSampleFactory(name, v.Interface().(SampleFactory))
+	return nil
+}
+
+//解码后的数据按类型赋值,map 的 key 由字符串转换为对应类型
+func sampleAssign(v reflect.Value, raw interface{}) error {
+	if raw == nil {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		return sampleAssign(v.Elem(), raw)
+	case reflect.Struct:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s need object,got %T", v.Type(), raw)
+		}
+		for name, value := range obj {
+			if field := v.FieldByName(name); field.IsValid() {
+				if err := sampleAssign(field, value); err != nil {
+					return fmt.Errorf("%s.%s,%v", v.Type(), name, err)
+				}
+			}
+		}
+	case reflect.Slice:
+		list, ok := raw.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s need array,got %T", v.Type(), raw)
+		}
+		v.Set(reflect.MakeSlice(v.Type(), len(list), len(list)))
+		for i, value := range list {
+			if err := sampleAssign(v.Index(i), value); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s need object,got %T", v.Type(), raw)
+		}
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(obj)))
+		if v.Type().Key().Kind() == reflect.Struct {
+			//组合主键的数据按主键字段逐层嵌套
+			return sampleAssignNested(v, reflect.New(v.Type().Key()).Elem(), 0, obj)
+		}
+		for k, value := range obj {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := sampleAssign(key, k); err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := sampleAssign(elem, value); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+	default:
+		s := fmt.Sprint(raw)
+		switch v.Kind() {
+		case reflect.String:
+			v.SetString(s)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return err
+			}
+			v.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+			if err != nil {
+				return err
+			}
+			v.SetInt(i)
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(s, v.Type().Bits())
+			if err != nil {
+				return err
+			}
+			v.SetFloat(f)
+		default:
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+	}
+	return nil
+}
+
+//组合主键 {"主键1":{"主键2":{...}}},key 的第 depth 个字段对应第 depth 层
+func sampleAssignNested(m reflect.Value, key reflect.Value, depth int, obj map[string]interface{}) error {
+	for k, value := range obj {
+		if err := sampleAssign(key.Field(depth), k); err != nil {
+			return err
+		}
+		if depth == key.NumField()-1 {
+			elem := reflect.New(m.Type().Elem()).Elem()
+			if err := sampleAssign(elem, value); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, elem)
+			continue
+		}
+		son, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s need object,got %T", m.Type(), value)
+		}
+		if err := sampleAssignNested(m, key, depth+1, son); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+`, "\n"))
+}
+
+// 输出数据文件对应的模板名列表,加载器只加载这些模板 eg: var jsonSampleNames = []string{"SF_X"}
+func generateGoSampleNames(outputf func(s string), varName, desc string, sheetNames []string) {
+	outputf(fmt.Sprintf("\n//%s\nvar %s = []string{\n", desc, varName))
+	for _, sheetName := range sheetNames {
+		outputf(fmt.Sprintf("\t%q,\n", "SF_"+sheetName))
+	}
+	outputf("}\n")
+}
@@ -0,0 +1,238 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 输出字段注释,结构体字段递归输出子标签的字段注释
+func (g *Generator) generateLuaDescFromXLSXFile(wb *Workbook, sheetName string, outputf func(s string), indent string) error {
+	sheet, err := wb.Sheet(sheetName)
+	if err != nil {
+		return err
+	}
+	return g.generateLuaDesc(sheet, outputf, indent, map[string]bool{sheetName: true})
+}
+
+func (g *Generator) generateLuaDesc(sheet *Sheet, outputf func(s string), indent string, parents map[string]bool) error {
+	for _, field := range sheet.Fields {
+		outputf(fmt.Sprintf("\n%sP_%s:%s", indent, field.Name, field.Desc))
+		if leaf := field.Type.Leaf(); leaf.Kind == TypeStruct && !parents[leaf.Name] {
+			son, err := sheet.wb.Sheet(leaf.Name)
+			if err != nil {
+				return err
+			}
+			parents[leaf.Name] = true
+			err = g.generateLuaDesc(son, outputf, indent+g.Indent, parents)
+			delete(parents, leaf.Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 输出一行数据的所有字段,未设置的可选字段省略
+func (g *Generator) generateLuaContentFromXLSXRow(record *Record, outputf func(s string), indent string) {
+	for i, field := range record.Sheet.Fields {
+		if record.Values[i] == nil {
+			continue
+		}
+		outputf(fmt.Sprintf("\n%sP_%s=%s,", indent, field.Name, g.luaValue(record.Values[i], indent)))
+	}
+}
+
+// 输出标签所有数据 ["主键"]={...},组合主键逐层嵌套 ["主键1"]={["主键2"]={...},},
+func (g *Generator) generateLuaContentFromXLSXFile(records []*Record, outputf func(s string)) {
+	g.generateLuaKeyNodes(keyTree(records, 0), outputf, g.Indent)
+}
+
+func (g *Generator) generateLuaKeyNodes(nodes []*keyNode, outputf func(s string), indent string) {
+	for _, node := range nodes {
+		outputf(fmt.Sprintf("\n%s[%s]={", indent, luaQuote(node.Key.String())))
+		if node.Record != nil {
+			g.generateLuaContentFromXLSXRow(node.Record, outputf, indent+g.Indent)
+		} else {
+			g.generateLuaKeyNodes(node.Children, outputf, indent+g.Indent)
+		}
+		outputf(fmt.Sprintf("\n%s},", indent))
+	}
+}
+
+// 输出 index、group 字段的索引表 S_<标签名>_By<字段名>,值引用 S_<标签名> 中的数据
+// eg: S_X_ByName={["a"]=S_X["1"],}  S_X_ByType={["1"]={S_X["1"],S_X["2"],},}
+func (g *Generator) generateLuaIndex(sheet *Sheet, records []*Record, outputf func(s string)) {
+	for _, field := range sheet.Indexes() {
+		table := fmt.Sprintf("S_%s_By%s", sheet.Name, field.Name)
+		if !field.Constraints.Group {
+			outputf(fmt.Sprintf("\n---@type table<string, S_%s>\n%s={", sheet.Name, table))
+			for _, record := range records {
+				outputf(fmt.Sprintf("\n%s[%s]=%s,", g.Indent, luaQuote(record.Values[field.Index].String()), luaRecordPath(record)))
+			}
+			outputf("\n}\n")
+			continue
+		}
+		//分组按表格顺序
+		keys := make([]string, 0)
+		groups := make(map[string][]string)
+		for _, record := range records {
+			key := record.Values[field.Index].String()
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], luaRecordPath(record))
+		}
+		outputf(fmt.Sprintf("\n---@type table<string, S_%s[]>\n%s={", sheet.Name, table))
+		for _, key := range keys {
+			outputf(fmt.Sprintf("\n%s[%s]={", g.Indent, luaQuote(key)))
+			for _, path := range groups[key] {
+				outputf(fmt.Sprintf("\n%s%s%s,", g.Indent, g.Indent, path))
+			}
+			outputf(fmt.Sprintf("\n%s},", g.Indent))
+		}
+		outputf("\n}\n")
+	}
+}
+
+// 数据行在 S_<标签名> 中的位置 eg: S_X["1001"]["3"]
+func luaRecordPath(record *Record) string {
+	path := "S_" + record.Sheet.Name
+	for _, key := range record.Sheet.Keys {
+		path += "[" + luaQuote(record.Values[key.Index].String()) + "]"
+	}
+	return path
+}
+
+// 标签数据的注解类型,组合主键逐层嵌套 eg: table<string, table<string, S_X>>
+func luaSheetType(sheet *Sheet) string {
+	t := "S_" + sheet.Name
+	for range sheet.Keys {
+		t = fmt.Sprintf("table<string, %s>", t)
+	}
+	return t
+}
+
+// 数据转换为 lua 表达式,indent 为当前节点所在层级
+func (g *Generator) luaValue(v *Value, indent string) string {
+	var buf strings.Builder
+	switch v.Type.Kind {
+	case TypeScalar:
+		if s, ok := v.Scalar.(string); ok {
+			return luaString(s)
+		}
+		return v.String()
+	case TypeStruct:
+		buf.WriteString("{")
+		g.generateLuaContentFromXLSXRow(v.Record, func(s string) { buf.WriteString(s) }, indent+g.Indent)
+		buf.WriteString(fmt.Sprintf("\n%s}", indent))
+	case TypeSlice:
+		buf.WriteString("{")
+		if v.Type.IsFlat() {
+			//基础数据类型的数组单行输出 {1,2,3} {{1,2},{3},}
+			for i, elem := range v.List {
+				if elem.Type.Kind == TypeScalar {
+					if i > 0 {
+						buf.WriteString(",")
+					}
+					buf.WriteString(g.luaValue(elem, indent))
+				} else {
+					buf.WriteString(g.luaValue(elem, indent) + ",")
+				}
+			}
+			buf.WriteString("}")
+			break
+		}
+		for _, elem := range v.List {
+			buf.WriteString(fmt.Sprintf("\n%s%s%s,", indent, g.Indent, g.luaValue(elem, indent+g.Indent)))
+		}
+		buf.WriteString(fmt.Sprintf("\n%s}", indent))
+	case TypeMap:
+		buf.WriteString("{")
+		for i, elem := range v.List {
+			buf.WriteString(fmt.Sprintf("\n%s%s[%s]=%s,", indent, g.Indent, luaQuote(v.Keys[i].String()), g.luaValue(elem, indent+g.Indent)))
+		}
+		buf.WriteString(fmt.Sprintf("\n%s}", indent))
+	}
+	return buf.String()
+}
+
+// lua 长字符串 [[...]],内容中含有 ]] 或以 ] 结尾时使用 [=[...]=]
+func luaString(s string) string {
+	level := ""
+	for strings.Contains(s, "]"+level+"]") || strings.HasSuffix(s, "]"+level) {
+		level += "="
+	}
+	return fmt.Sprintf("[%s[%s]%s]", level, s, level)
+}
+
+// lua 表的字符串 key
+func luaQuote(s string) string {
+	return strconv.Quote(s)
+}
+
+// EmmyLua/LuaLS 注解类型,数据中 map 的 key 统一输出为字符串
+func luaAnnotationType(t *Type) string {
+	switch t.Kind {
+	case TypeSlice:
+		elem := luaAnnotationType(t.Elem)
+		if t.Elem.Kind == TypeMap {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case TypeMap:
+		return fmt.Sprintf("table<string, %s>", luaAnnotationType(t.Elem))
+	case TypeStruct:
+		return "S_" + t.Name
+	}
+	if t.Enum != "" {
+		return "E_" + t.Enum
+	}
+	switch t.Name {
+	case "string":
+		return "string"
+	case "bool":
+		return "boolean"
+	case "float32", "float64":
+		return "number"
+	}
+	return "integer"
+}
+
+// 输出标签的 ---@class 注解,返回需要继续输出的子标签
+func generateLuaAnnotationFromXLSXFile(sheet *Sheet, outputf func(s string), parsedSheetMap map[string]bool) (addParseSheetArray []string) {
+	outputf(fmt.Sprintf("\n---@class S_%s\n", sheet.Name))
+	for _, field := range sheet.Fields {
+		optional := ""
+		if field.Optional() {
+			optional = "?"
+		}
+		outputf(fmt.Sprintf("---@field P_%s%s %s", field.Name, optional, luaAnnotationType(field.Type)))
+		if desc := strings.TrimSpace(field.Desc); desc != "" {
+			outputf(" @" + strings.Join(strings.Fields(desc), " "))
+		}
+		outputf("\n")
+		if leaf := field.Type.Leaf(); leaf.Kind == TypeStruct && !parsedSheetMap[leaf.Name] {
+			parsedSheetMap[leaf.Name] = true
+			addParseSheetArray = append(addParseSheetArray, leaf.Name)
+		}
+	}
+	return
+}
+
+// 输出枚举表 E_<枚举名>={名称=值},数据中的枚举字段为枚举值
+func (g *Generator) generateLuaEnum(enum *Enum, outputf func(s string)) {
+	outputf(fmt.Sprintf("\n---@enum E_%s\nE_%s={", enum.Name, enum.Name))
+	for _, value := range enum.Values {
+		outputf(fmt.Sprintf("\n%s%s=%d,", g.Indent, value.Name, value.Value))
+		if desc := strings.TrimSpace(value.Desc); desc != "" {
+			outputf(" --" + strings.Join(strings.Fields(desc), " "))
+		}
+	}
+	outputf("\n}\n")
+}
@@ -0,0 +1,384 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// 输出标签所有数据,结构与 lua、json 相同: {"主键":{"P_字段名":值}},map 的 key 为字符串
+func generateMsgpackContent(records []*Record, buf *bytes.Buffer) {
+	msgpackKeyNodes(buf, keyTree(records, 0))
+}
+
+func msgpackKeyNodes(buf *bytes.Buffer, nodes []*keyNode) {
+	mpMapHeader(buf, len(nodes))
+	for _, node := range nodes {
+		mpString(buf, node.Key.String())
+		if node.Record != nil {
+			msgpackRecord(buf, node.Record)
+		} else {
+			msgpackKeyNodes(buf, node.Children)
+		}
+	}
+}
+
+// 未设置的可选字段省略
+func msgpackRecord(buf *bytes.Buffer, record *Record) {
+	n := 0
+	for _, v := range record.Values {
+		if v != nil {
+			n++
+		}
+	}
+	mpMapHeader(buf, n)
+	for i, field := range record.Sheet.Fields {
+		if record.Values[i] == nil {
+			continue
+		}
+		mpString(buf, "P_"+field.Name)
+		msgpackValue(buf, record.Values[i])
+	}
+}
+
+func msgpackValue(buf *bytes.Buffer, v *Value) {
+	switch v.Type.Kind {
+	case TypeScalar:
+		switch s := v.Scalar.(type) {
+		case int64:
+			mpInt(buf, s)
+		case float64:
+			mpFloat(buf, s)
+		case bool:
+			mpBool(buf, s)
+		case string:
+			mpString(buf, s)
+		}
+	case TypeStruct:
+		msgpackRecord(buf, v.Record)
+	case TypeSlice:
+		mpArrayHeader(buf, len(v.List))
+		for _, elem := range v.List {
+			msgpackValue(buf, elem)
+		}
+	case TypeMap:
+		mpMapHeader(buf, len(v.List))
+		for i, elem := range v.List {
+			mpString(buf, v.Keys[i].String())
+			msgpackValue(buf, elem)
+		}
+	}
+}
+
+func mpBool(buf *bytes.Buffer, b bool) {
+	if b {
+		buf.WriteByte(0xc3)
+	} else {
+		buf.WriteByte(0xc2)
+	}
+}
+
+// 整数按取值范围使用最短的编码
+func mpInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= 0x7f:
+		buf.WriteByte(byte(i))
+	case i >= -32 && i < 0:
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+// 浮点数统一使用 float64,保留表格中的精度
+func mpFloat(buf *bytes.Buffer, f float64) {
+	buf.WriteByte(0xcb)
+	binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+}
+
+func mpString(buf *bytes.Buffer, s string) {
+	n := len(s)
+	switch {
+	case n <= 31:
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+	buf.WriteString(s)
+}
+
+func mpArrayHeader(buf *bytes.Buffer, n int) {
+	switch {
+	case n <= 15:
+		buf.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xdc)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdd)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func mpMapHeader(buf *bytes.Buffer, n int) {
+	switch {
+	case n <= 15:
+		buf.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xde)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdf)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// 输出 lua 加载器,使用 lua-MessagePack(require 'MessagePack') 解码
+func generateLuaMsgpackLoader(outputf func(s string), sheetNames []string) {
+	quoted := make([]string, 0, len(sheetNames))
+	for _, sheetName := range sheetNames {
+		quoted = append(quoted, luaQuote(sheetName))
+	}
+	outputf(fmt.Sprintf(strings.TrimLeft(`
+--[[
+Code generated by xlsx-parser.
+source: github.com/zxfonline/xlsx_parser
+DO NOT EDIT!
+]]
+
+local mp = require 'MessagePack'
+
+local M = {}
+
+--所有标签名
+M.sheets = {%s}
+
+--从 dir 目录加载 sample_<name>.msgpack,返回与 sample_<name>.lua 中 S_<name> 相同结构的表
+function M.load(dir, name)
+	local f = assert(io.open(dir .. "/sample_" .. name .. ".msgpack", "rb"))
+	local data = f:read("*a")
+	f:close()
+	return mp.unpack(data)
+end
+
+--加载所有标签数据到全局变量 S_<name>
+function M.load_all(dir)
+	for _, name in ipairs(M.sheets) do
+		_G["S_" .. name] = M.load(dir, name)
+	end
+end
+
+return M
+`, "\n"), strings.Join(quoted, ",")))
+}
+
+// 输出 LoadMsgpack(dir),从 msgpack 文件加载 sheetNames 对应的模板数据
+func generateGoMsgpackLoader(outputf func(s string), sheetNames []string) {
+	outputf(strings.TrimLeft(`
+//Code generated by xlsx-parser.
+//source: github.com/zxfonline/xlsx_parser
+//DO NOT EDIT!
+
+package sample
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+)
+
+//从 dir 目录下的 sample_<标签名>.msgpack 加载所有导出了 msgpack 的模板数据
+func LoadMsgpack(dir string) error {
+	for _, name := range msgpackSampleNames {
+		pathfile := filepath.Join(dir, sampleFileName(name, ".msgpack"))
+		data, err := ioutil.ReadFile(pathfile)
+		if err != nil {
+			return err
+		}
+		d := &msgpackDecoder{data: data}
+		raw, err := d.decode()
+		if err != nil {
+			return fmt.Errorf("load %s,err:%v", pathfile, err)
+		}
+		if err := loadSample(name, raw); err != nil {
+			return fmt.Errorf("load %s,err:%v", pathfile, err)
+		}
+	}
+	return nil
+}
+
+//msgpack 解码,map 解码为 map[string]interface{}
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if d.pos+n > len(d.data) {
+		return nil, fmt.Errorf("msgpack unexpected end of data")
+	}
+	bs := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return bs, nil
+}
+
+func (d *msgpackDecoder) uint(n int) (uint64, error) {
+	bs, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 1:
+		return uint64(bs[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(bs)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(bs)), nil
+	}
+	return binary.BigEndian.Uint64(bs), nil
+}
+
+func (d *msgpackDecoder) decode() (interface{}, error) {
+	bs, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := bs[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c >= 0x80 && c <= 0x8f:
+		return d.decodeMap(int(c & 0x0f))
+	case c >= 0x90 && c <= 0x9f:
+		return d.decodeArray(int(c & 0x0f))
+	case c >= 0xa0 && c <= 0xbf:
+		return d.decodeString(int(c & 0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6, 0xd9, 0xda, 0xdb:
+		size := map[byte]int{0xc4: 1, 0xc5: 2, 0xc6: 4, 0xd9: 1, 0xda: 2, 0xdb: 4}[c]
+		n, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xca:
+		n, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 0xcb:
+		n, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(n), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		switch size {
+		case 1:
+			return int64(int8(n)), nil
+		case 2:
+			return int64(int16(n)), nil
+		case 4:
+			return int64(int32(n)), nil
+		}
+		return int64(n), nil
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n))
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n))
+	}
+	return nil, fmt.Errorf("msgpack unsupported format 0x%x", c)
+}
+
+func (d *msgpackDecoder) decodeString(n int) (interface{}, error) {
+	bs, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(bs), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n int) (interface{}, error) {
+	list := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int) (interface{}, error) {
+	obj := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		obj[fmt.Sprint(k)] = v
+	}
+	return obj, nil
+}
+`, "\n"))
+	generateGoSampleNames(outputf, "msgpackSampleNames", "导出了 msgpack 数据文件的模板名", sheetNames)
+}
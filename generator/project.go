@@ -0,0 +1,207 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 项目配置文件,支持 yaml 和 json 格式(按扩展名区分)
+// eg:
+//
+//	output:
+//	  go: ./gen_config
+//	  lua: ./lua
+//	  json: ./json
+//	  msgpack: ./msgpack
+//	  proto: ./proto
+//	  cs: ./cs
+//	  ts: ./ts
+//	  go_data: true
+//	map_sep: "="
+//	array_sep: ","
+//	token_begin: "["
+//	token_end: "]"
+//	indent: "\t"
+//	workbooks:
+//	  - path: ./tst1.xlsx
+//	  - path: ./TST2.xlsx
+//	    sheets:
+//	      - name: TST2
+//	      - name: Tst3
+//	        skip_lua: true
+//	inputs:
+//	  - ./design/**/*.xlsx
+//	cache: ./.xlsx_parser.cache
+type Project struct {
+	Output struct {
+		Go  string `json:"go" yaml:"go"`
+		Lua string `json:"lua" yaml:"lua"`
+		//json 输出目录,为空时不输出
+		JSON string `json:"json" yaml:"json"`
+		//msgpack 输出目录,为空时不输出
+		Msgpack string `json:"msgpack" yaml:"msgpack"`
+		//protobuf 输出目录,为空时不输出
+		Proto string `json:"proto" yaml:"proto"`
+		//.proto 文件的 package,默认 sample
+		ProtoPackage string `json:"proto_package" yaml:"proto_package"`
+		//C# 输出目录,为空时不输出
+		CS string `json:"cs" yaml:"cs"`
+		//C# 的 namespace,默认 Sample
+		CSNamespace string `json:"cs_namespace" yaml:"cs_namespace"`
+		//TypeScript 输出目录,为空时不输出
+		TS string `json:"ts" yaml:"ts"`
+		//同时输出 golang 模板数据
+		GoData bool `json:"go_data" yaml:"go_data"`
+	} `json:"output" yaml:"output"`
+	MapSeparator     string `json:"map_sep" yaml:"map_sep"`
+	ArraySeparator   string `json:"array_sep" yaml:"array_sep"`
+	ArraysTokenBegin string `json:"token_begin" yaml:"token_begin"`
+	ArraysTokenEnd   string `json:"token_end" yaml:"token_end"`
+	Indent           string `json:"indent" yaml:"indent"`
+	//需要导出的excel文件,相对路径以项目配置文件所在目录为准
+	Workbooks []ProjectWorkbook `json:"workbooks" yaml:"workbooks"`
+	//自动查找的excel文件,支持目录及通配符
+	Inputs []string `json:"inputs" yaml:"inputs"`
+	//增量导出缓存文件,默认为 golang 输出目录下的 .xlsx_parser.cache
+	Cache string `json:"cache" yaml:"cache"`
+}
+
+// 项目配置中的excel文件
+type ProjectWorkbook struct {
+	Path string `json:"path" yaml:"path"`
+	//需要导出的标签,为空时以文件名作为标签名
+	Sheets []ProjectSheet `json:"sheets" yaml:"sheets"`
+}
+
+// 项目配置中的标签及其导出选项
+type ProjectSheet struct {
+	Name        string `json:"name" yaml:"name"`
+	SheetOption `yaml:",inline"`
+}
+
+// 读取项目配置文件
+func LoadProject(pathfile string) (*Project, error) {
+	data, err := ioutil.ReadFile(pathfile)
+	if err != nil {
+		return nil, err
+	}
+	p := &Project{}
+	switch strings.ToLower(filepath.Ext(pathfile)) {
+	case ".json":
+		//与 yaml 相同,不允许未知的配置项
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(p)
+	default:
+		err = yaml.UnmarshalStrict(data, p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid project file %s,err:%v", pathfile, err)
+	}
+	dir := filepath.Dir(pathfile)
+	p.Output.Go = resolvePath(dir, p.Output.Go)
+	p.Output.Lua = resolvePath(dir, p.Output.Lua)
+	p.Output.JSON = resolvePath(dir, p.Output.JSON)
+	p.Output.Msgpack = resolvePath(dir, p.Output.Msgpack)
+	p.Output.Proto = resolvePath(dir, p.Output.Proto)
+	p.Output.CS = resolvePath(dir, p.Output.CS)
+	p.Output.TS = resolvePath(dir, p.Output.TS)
+	p.Cache = resolvePath(dir, p.Cache)
+	for i := range p.Workbooks {
+		p.Workbooks[i].Path = resolvePath(dir, p.Workbooks[i].Path)
+	}
+	for i := range p.Inputs {
+		p.Inputs[i] = resolvePath(dir, p.Inputs[i])
+	}
+	return p, nil
+}
+
+// 相对路径转换为相对于 dir 的路径
+func resolvePath(dir, pathfile string) string {
+	if pathfile == "" || filepath.IsAbs(pathfile) {
+		return pathfile
+	}
+	return filepath.Join(dir, pathfile)
+}
+
+// 项目配置转换为生成器配置,未配置的项使用默认值
+func (p *Project) Config() (*Config, error) {
+	cfg := DefaultConfig()
+	if p.Output.Go != "" {
+		cfg.OutGoPath = p.Output.Go
+	}
+	if p.Output.Lua != "" {
+		cfg.OutLuaPath = p.Output.Lua
+	}
+	if p.Output.JSON != "" {
+		cfg.OutJSONPath = p.Output.JSON
+	}
+	if p.Output.Msgpack != "" {
+		cfg.OutMsgpackPath = p.Output.Msgpack
+	}
+	if p.Output.Proto != "" {
+		cfg.OutProtoPath = p.Output.Proto
+	}
+	if p.Output.ProtoPackage != "" {
+		cfg.ProtoPackage = p.Output.ProtoPackage
+	}
+	if p.Output.CS != "" {
+		cfg.OutCSPath = p.Output.CS
+	}
+	if p.Output.CSNamespace != "" {
+		cfg.CSNamespace = p.Output.CSNamespace
+	}
+	if p.Output.TS != "" {
+		cfg.OutTSPath = p.Output.TS
+	}
+	cfg.GoData = p.Output.GoData
+	if p.MapSeparator != "" {
+		cfg.MapSeparator = p.MapSeparator
+	}
+	if p.ArraySeparator != "" {
+		cfg.ArraySeparator = p.ArraySeparator
+	}
+	if p.ArraysTokenBegin != "" {
+		cfg.ArraysTokenBegin = p.ArraysTokenBegin
+	}
+	if p.ArraysTokenEnd != "" {
+		cfg.ArraysTokenEnd = p.ArraysTokenEnd
+	}
+	if p.Indent != "" {
+		cfg.Indent = p.Indent
+	}
+	cfg.CacheFile = p.Cache
+	cfg.Inputs = append(cfg.Inputs, p.Inputs...)
+	for _, wb := range p.Workbooks {
+		if strings.TrimSpace(wb.Path) == "" {
+			return nil, fmt.Errorf("invalid project,err:empty workbook path.")
+		}
+		if len(wb.Sheets) == 0 {
+			pathfile := CleanPath(wb.Path)
+			sheetName := strings.TrimSuffix(path.Base(pathfile), path.Ext(pathfile))
+			if err := cfg.AddExcel(pathfile, sheetName); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, sheet := range wb.Sheets {
+			if err := cfg.AddExcel(wb.Path, sheet.Name); err != nil {
+				return nil, err
+			}
+			option := sheet.SheetOption
+			cfg.SheetOptions[sheet.Name] = &option
+		}
+	}
+	return cfg, nil
+}
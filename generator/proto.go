@@ -0,0 +1,366 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// 字段编号锁定文件,保证重新生成时字段编号不变
+// 已删除字段的编号保留不再使用,输出为 reserved
+type protoLock struct {
+	//消息名 -> 字段名 -> 字段编号
+	Messages map[string]map[string]int `json:"messages"`
+}
+
+func loadProtoLock(pathfile string) (*protoLock, error) {
+	lock := &protoLock{Messages: make(map[string]map[string]int)}
+	data, err := ioutil.ReadFile(pathfile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("invalid proto lock file %s,err:%v", pathfile, err)
+	}
+	if lock.Messages == nil {
+		lock.Messages = make(map[string]map[string]int)
+	}
+	return lock, nil
+}
+
+func (l *protoLock) save(pathfile string) error {
+	data, err := json.MarshalIndent(l, "", "\t")
+	if err != nil {
+		return err
+	}
+	wc, err := openFile(pathfile)
+	if err != nil {
+		return err
+	}
+	defer wc.Close()
+	_, err = wc.Write(append(data, '\n'))
+	return err
+}
+
+// 字段编号,新字段使用该消息已分配过的最大编号 +1
+func (l *protoLock) number(message, field string) int {
+	fields, ok := l.Messages[message]
+	if !ok {
+		fields = make(map[string]int)
+		l.Messages[message] = fields
+	}
+	if n, ok := fields[field]; ok {
+		return n
+	}
+	n := 1
+	for _, v := range fields {
+		if v >= n {
+			n = v + 1
+		}
+	}
+	//19000-19999 为 protobuf 保留编号
+	if n >= 19000 && n <= 19999 {
+		n = 20000
+	}
+	fields[field] = n
+	return n
+}
+
+// .proto 结构定义,标签对应 message S_<标签名>,导出的标签另有容器 message SF_<标签名>
+type protoSchema struct {
+	lock *protoLock
+	//标签名 -> 字段名 -> 字段编号
+	numbers map[string]map[string]int
+	//已输出的 message 定义
+	messages []string
+	//已定义的 message 名
+	defined map[string]bool
+}
+
+func newProtoSchema(lock *protoLock) *protoSchema {
+	return &protoSchema{
+		lock:    lock,
+		numbers: make(map[string]map[string]int),
+		defined: make(map[string]bool),
+	}
+}
+
+// 基础数据类型对应的 protobuf 类型
+func protoScalar(t *Type) string {
+	switch t.Name {
+	case "int8", "int16", "int32":
+		return "int32"
+	case "int64", "int":
+		return "int64"
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	}
+	return t.Name
+}
+
+// 类型对应的 message 名称片段 eg: []int => List_int64 map[int]string => Map_int64_string
+func protoName(t *Type) string {
+	switch t.Kind {
+	case TypeSlice:
+		return "List_" + protoName(t.Elem)
+	case TypeMap:
+		return "Map_" + protoName(t.Key) + "_" + protoName(t.Elem)
+	case TypeStruct:
+		return "S_" + t.Name
+	}
+	return protoScalar(t)
+}
+
+// protobuf map 的 key 只能是整数、字符串、bool,其他类型的 map 使用 repeated Entry
+func protoMapKey(t *Type) bool {
+	return t.Kind == TypeScalar && !t.IsFloat()
+}
+
+// 数组元素、map 值的 protobuf 类型,嵌套的数组、map 使用包装 message
+func (ps *protoSchema) elemType(t *Type) string {
+	switch t.Kind {
+	case TypeScalar:
+		return protoScalar(t)
+	case TypeStruct:
+		return "S_" + t.Name
+	}
+	name := protoName(t)
+	if !ps.defined[name] {
+		ps.defined[name] = true
+		ps.messages = append(ps.messages, fmt.Sprintf("message %s {\n\t%s\n}\n", name, ps.fieldDecl(t, "items", 1)))
+	}
+	return name
+}
+
+// 字段定义 eg: repeated int32 Ids = 1;
+func (ps *protoSchema) fieldDecl(t *Type, name string, number int) string {
+	switch t.Kind {
+	case TypeSlice:
+		return fmt.Sprintf("repeated %s %s = %d;", ps.elemType(t.Elem), name, number)
+	case TypeMap:
+		if protoMapKey(t.Key) {
+			return fmt.Sprintf("map<%s, %s> %s = %d;", protoScalar(t.Key), ps.elemType(t.Elem), name, number)
+		}
+		entry := "Entry_" + protoName(t.Key) + "_" + protoName(t.Elem)
+		if !ps.defined[entry] {
+			ps.defined[entry] = true
+			ps.messages = append(ps.messages, fmt.Sprintf("message %s {\n\t%s key = 1;\n\t%s value = 2;\n}\n", entry, protoScalar(t.Key), ps.elemType(t.Elem)))
+		}
+		return fmt.Sprintf("repeated %s %s = %d;", entry, name, number)
+	}
+	return fmt.Sprintf("%s %s = %d;", ps.elemType(t), name, number)
+}
+
+// 添加标签对应的 message,引用的子标签一并添加
+func (ps *protoSchema) addSheet(sheet *Sheet) error {
+	name := "S_" + sheet.Name
+	if ps.defined[name] {
+		return nil
+	}
+	ps.defined[name] = true
+	numbers := make(map[string]int)
+	ps.numbers[sheet.Name] = numbers
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("message %s {\n", name))
+	used := make(map[int]bool)
+	for _, field := range sheet.Fields {
+		n := ps.lock.number(name, field.Name)
+		numbers[field.Name] = n
+		used[n] = true
+		if field.Desc != "" {
+			buf.WriteString(fmt.Sprintf("\t//%s\n", strings.Replace(field.Desc, "\n", " ", -1)))
+		}
+		if field.Optional() && field.Type.Kind == TypeScalar {
+			//可选的基础数据类型需要区分未设置与零值
+			buf.WriteString(fmt.Sprintf("\toptional %s\n", ps.fieldDecl(field.Type, field.Name, n)))
+		} else {
+			buf.WriteString(fmt.Sprintf("\t%s\n", ps.fieldDecl(field.Type, field.Name, n)))
+		}
+	}
+	//已删除字段的编号不再使用
+	reserved := make([]int, 0)
+	for _, n := range ps.lock.Messages[name] {
+		if !used[n] {
+			reserved = append(reserved, n)
+		}
+	}
+	if len(reserved) > 0 {
+		sort.Ints(reserved)
+		strs := make([]string, 0, len(reserved))
+		for _, n := range reserved {
+			strs = append(strs, fmt.Sprint(n))
+		}
+		buf.WriteString(fmt.Sprintf("\treserved %s;\n", strings.Join(strs, ", ")))
+	}
+	buf.WriteString("}\n")
+	ps.messages = append(ps.messages, buf.String())
+	for _, field := range sheet.Fields {
+		if leaf := field.Type.Leaf(); leaf.Kind == TypeStruct {
+			son, err := sheet.wb.Sheet(leaf.Name)
+			if err != nil {
+				return err
+			}
+			if err := ps.addSheet(son); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 导出标签的容器 message,数据文件 sample_<标签名>.pb 即为该 message
+func (ps *protoSchema) addContainer(sheet *Sheet) {
+	ps.messages = append(ps.messages, fmt.Sprintf("message SF_%s {\n\trepeated S_%s rows = 1;\n}\n", sheet.Name, sheet.Name))
+}
+
+// 输出 .proto 文件内容
+func (ps *protoSchema) generate(outputf func(s string), pkg string) {
+	outputf("//Code generated by xlsx-parser.\n")
+	outputf("//source: github.com/zxfonline/xlsx_parser\n")
+	outputf("//DO NOT EDIT!\n\n")
+	outputf("syntax = \"proto3\";\n\n")
+	outputf(fmt.Sprintf("package %s;\n\n", pkg))
+	outputf(fmt.Sprintf("option go_package = \"./%s\";\n", pkg))
+	for _, message := range ps.messages {
+		outputf("\n" + message)
+	}
+}
+
+// 编码标签所有数据为容器 message SF_<标签名>
+func (ps *protoSchema) encodeRecords(records []*Record) []byte {
+	var buf bytes.Buffer
+	for _, record := range records {
+		pbBytes(&buf, 1, ps.encodeRecord(record))
+	}
+	return buf.Bytes()
+}
+
+func (ps *protoSchema) encodeRecord(record *Record) []byte {
+	var buf bytes.Buffer
+	numbers := ps.numbers[record.Sheet.Name]
+	for i, field := range record.Sheet.Fields {
+		v := record.Values[i]
+		switch {
+		case v == nil:
+			//未设置的可选字段
+		case field.Optional() && field.Type.Kind == TypeScalar:
+			ps.encodeSingle(&buf, numbers[field.Name], field.Type, v, false)
+		default:
+			ps.encodeField(&buf, numbers[field.Name], field.Type, v)
+		}
+	}
+	return buf.Bytes()
+}
+
+// 编码字段,基础数据类型的零值省略
+func (ps *protoSchema) encodeField(buf *bytes.Buffer, number int, t *Type, v *Value) {
+	switch t.Kind {
+	case TypeSlice:
+		if t.Elem.Kind == TypeScalar && t.Elem.Name != "string" {
+			//数值类型的数组使用 packed 编码
+			if len(v.List) == 0 {
+				return
+			}
+			var packed bytes.Buffer
+			for _, elem := range v.List {
+				pbScalar(&packed, elem)
+			}
+			pbBytes(buf, number, packed.Bytes())
+			return
+		}
+		for _, elem := range v.List {
+			ps.encodeSingle(buf, number, t.Elem, elem, false)
+		}
+	case TypeMap:
+		//map 与 repeated Entry 的编码相同
+		for i, elem := range v.List {
+			var entry bytes.Buffer
+			ps.encodeSingle(&entry, 1, t.Key, v.Keys[i], false)
+			ps.encodeSingle(&entry, 2, t.Elem, elem, false)
+			pbBytes(buf, number, entry.Bytes())
+		}
+	default:
+		ps.encodeSingle(buf, number, t, v, true)
+	}
+}
+
+// 编码单个值,嵌套的数组、map 编码为包装 message
+func (ps *protoSchema) encodeSingle(buf *bytes.Buffer, number int, t *Type, v *Value, omitZero bool) {
+	switch t.Kind {
+	case TypeScalar:
+		if omitZero && v.IsZero() {
+			return
+		}
+		switch s := v.Scalar.(type) {
+		case string:
+			pbBytes(buf, number, []byte(s))
+		case float64:
+			if t.Name == "float32" {
+				pbTag(buf, number, 5)
+			} else {
+				pbTag(buf, number, 1)
+			}
+			pbScalar(buf, v)
+		default:
+			pbTag(buf, number, 0)
+			pbScalar(buf, v)
+		}
+	case TypeStruct:
+		pbBytes(buf, number, ps.encodeRecord(v.Record))
+	default:
+		var wrapper bytes.Buffer
+		ps.encodeField(&wrapper, 1, t, v)
+		pbBytes(buf, number, wrapper.Bytes())
+	}
+}
+
+func pbTag(buf *bytes.Buffer, number int, wireType int) {
+	pbVarint(buf, uint64(number)<<3|uint64(wireType))
+}
+
+func pbVarint(buf *bytes.Buffer, x uint64) {
+	var bs [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(bs[:], x)
+	buf.Write(bs[:n])
+}
+
+func pbBytes(buf *bytes.Buffer, number int, data []byte) {
+	pbTag(buf, number, 2)
+	pbVarint(buf, uint64(len(data)))
+	buf.Write(data)
+}
+
+// 编码不带 tag 的数值
+func pbScalar(buf *bytes.Buffer, v *Value) {
+	switch s := v.Scalar.(type) {
+	case int64:
+		pbVarint(buf, uint64(s))
+	case bool:
+		if s {
+			pbVarint(buf, 1)
+		} else {
+			pbVarint(buf, 0)
+		}
+	case float64:
+		if v.Type.Name == "float32" {
+			binary.Write(buf, binary.LittleEndian, math.Float32bits(float32(s)))
+		} else {
+			binary.Write(buf, binary.LittleEndian, math.Float64bits(s))
+		}
+	}
+}
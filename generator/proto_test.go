@@ -0,0 +1,294 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 测试用的 protobuf 解码,按 wire format 逐字节解析,不依赖 pbVarint 的实现
+type pbField struct {
+	number int
+	wire   int
+	//varint、fixed32、fixed64 的值
+	num uint64
+	//length-delimited 的内容
+	data []byte
+}
+
+func pbReadVarint(data []byte) (uint64, int, error) {
+	var x uint64
+	for i := 0; i < len(data) && i < 10; i++ {
+		b := data[i]
+		x |= uint64(b&0x7f) << (7 * uint(i))
+		if b < 0x80 {
+			return x, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid varint % x", data)
+}
+
+func pbDecode(data []byte) ([]pbField, error) {
+	fields := make([]pbField, 0)
+	for len(data) > 0 {
+		tag, n, err := pbReadVarint(data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[n:]
+		f := pbField{number: int(tag >> 3), wire: int(tag & 7)}
+		switch f.wire {
+		case 0:
+			if f.num, n, err = pbReadVarint(data); err != nil {
+				return nil, err
+			}
+		case 1, 5:
+			n = 8
+			if f.wire == 5 {
+				n = 4
+			}
+			if len(data) < n {
+				return nil, fmt.Errorf("truncated fixed%d", n*8)
+			}
+			for i := n - 1; i >= 0; i-- {
+				f.num = f.num<<8 | uint64(data[i])
+			}
+		case 2:
+			size, m, err := pbReadVarint(data)
+			if err != nil {
+				return nil, err
+			}
+			if uint64(len(data)-m) < size {
+				return nil, fmt.Errorf("truncated bytes,need %d", size)
+			}
+			f.data = data[m : m+int(size)]
+			n = m + int(size)
+		default:
+			return nil, fmt.Errorf("unknown wire type %d", f.wire)
+		}
+		data = data[n:]
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// 解码结果的文本形式 eg: 1:v=-5 2:"abc" 5:{1:v=1 2:"a"}
+// packed 数组及嵌套 message 由调用方按字段编号指定
+func pbString(t *testing.T, data []byte, packed map[int]bool, nested map[int]bool) string {
+	fields, err := pbDecode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := make([]string, 0, len(fields))
+	for _, f := range fields {
+		switch {
+		case f.wire == 0:
+			items = append(items, fmt.Sprintf("%d:v=%d", f.number, int64(f.num)))
+		case f.wire == 5:
+			items = append(items, fmt.Sprintf("%d:f32=%g", f.number, math.Float32frombits(uint32(f.num))))
+		case f.wire == 1:
+			items = append(items, fmt.Sprintf("%d:f64=%g", f.number, math.Float64frombits(f.num)))
+		case packed[f.number]:
+			vs := make([]string, 0)
+			for rest := f.data; len(rest) > 0; {
+				x, n, err := pbReadVarint(rest)
+				if err != nil {
+					t.Fatal(err)
+				}
+				vs = append(vs, fmt.Sprint(int64(x)))
+				rest = rest[n:]
+			}
+			items = append(items, fmt.Sprintf("%d:[%s]", f.number, strings.Join(vs, ",")))
+		case nested[f.number]:
+			items = append(items, fmt.Sprintf("%d:{%s}", f.number, pbString(t, f.data, nil, map[int]bool{})))
+		default:
+			items = append(items, fmt.Sprintf("%d:%q", f.number, f.data))
+		}
+	}
+	return strings.Join(items, " ")
+}
+
+func TestPbVarint(t *testing.T) {
+	tests := []struct {
+		x    uint64
+		want string
+	}{
+		{0, "00"},
+		{1, "01"},
+		{127, "7f"},
+		{128, "8001"},
+		{300, "ac02"},
+		{1<<32 - 1, "ffffffff0f"},
+		{math.MaxInt64, "ffffffffffffffff7f"},
+		//int32、int64 字段的负数不使用 zigzag,按 64 位补码编码为 10 字节
+		{uint64(1<<64 - 1), "ffffffffffffffffff01"},
+		{uint64(1<<64 - 2), "feffffffffffffffff01"},
+		{uint64(1 << 63), "80808080808080808001"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		pbVarint(&buf, tt.x)
+		if got := hex.EncodeToString(buf.Bytes()); got != tt.want {
+			t.Errorf("pbVarint(%d) = %s, want %s", tt.x, got, tt.want)
+		}
+		x, n, err := pbReadVarint(buf.Bytes())
+		if err != nil || x != tt.x || n != buf.Len() {
+			t.Errorf("decode pbVarint(%d) = %d, %d, %v", tt.x, x, n, err)
+		}
+	}
+}
+
+func TestPbScalar(t *testing.T) {
+	tests := []struct {
+		typ  string
+		cell string
+		want string
+	}{
+		{"int32", "0", "00"},
+		{"int32", "-1", "ffffffffffffffffff01"},
+		{"int32", "-2147483648", "80808080f8ffffffff01"},
+		{"int64", "-9223372036854775808", "80808080808080808001"},
+		{"bool", "true", "01"},
+		{"bool", "false", "00"},
+		{"float32", "1.5", "0000c03f"},
+		{"float64", "-2", "00000000000000c0"},
+	}
+	wb := &Workbook{g: newTestGenerator()}
+	for _, tt := range tests {
+		typ, _ := ParseType(tt.typ)
+		v, err := wb.decodeScalar(typ, tt.cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		pbScalar(&buf, v)
+		if got := hex.EncodeToString(buf.Bytes()); got != tt.want {
+			t.Errorf("pbScalar(%s %s) = %s, want %s", tt.typ, tt.cell, got, tt.want)
+		}
+	}
+}
+
+func TestPbBytes(t *testing.T) {
+	for _, size := range []int{0, 1, 127, 128, 300} {
+		data := bytes.Repeat([]byte{'x'}, size)
+		var buf bytes.Buffer
+		pbBytes(&buf, 20000, data)
+		fields, err := pbDecode(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fields) != 1 || fields[0].number != 20000 || fields[0].wire != 2 || !bytes.Equal(fields[0].data, data) {
+			t.Errorf("pbBytes size %d decode = %+v", size, fields)
+		}
+	}
+}
+
+func TestProtoEncodeRecords(t *testing.T) {
+	g := newTestGenerator()
+	wb := openTestWorkbook(t, g, "Item", strings.Join([]string{
+		"编号,名称,比例,列表,字典,嵌套,标记,可选",
+		"int32,string,float32,[]int,map[int]string,[][]int,bool optional,int32 optional",
+		"Id,Name,Rate,List,Map,Lists,Flag,Opt",
+		`-5,abc,0.5,"1,-2,300","1=a,2=b","[1],[]",,0`,
+		`0,,0,,,,false,`,
+	}, "\n"))
+	sheet, err := wb.Sheet("Item")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := sheet.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := newProtoSchema(&protoLock{Messages: make(map[string]map[string]int)})
+	if err := schema.addSheet(sheet); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := pbDecode(schema.encodeRecords(records))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`1:v=-5 2:"abc" 3:f32=0.5 4:[1,-2,300] 5:{1:v=1 2:"a"} 5:{1:v=2 2:"b"} 6:{1:"\x01"} 6:{} 8:v=0`,
+		//零值省略,已设置的可选字段保留零值
+		`7:v=0`,
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("encodeRecords rows = %d, want %d", len(rows), len(want))
+	}
+	for i, row := range rows {
+		if row.number != 1 || row.wire != 2 {
+			t.Fatalf("row %d tag = %d/%d", i, row.number, row.wire)
+		}
+		got := pbString(t, row.data, map[int]bool{4: true}, map[int]bool{5: true, 6: true})
+		if got != want[i] {
+			t.Errorf("row %d = %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestProtoLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xlsx_parser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	lock_path := filepath.Join(dir, "sample.proto.lock")
+
+	generate := func(types, names string) string {
+		g := newTestGenerator()
+		//描述行留空,不输出字段注释
+		desc := strings.Repeat(",", strings.Count(names, ","))
+		wb := openTestWorkbook(t, g, "Item", desc+"\n"+types+"\n"+names+"\n")
+		sheet, err := wb.Sheet("Item")
+		if err != nil {
+			t.Fatal(err)
+		}
+		lock, err := loadProtoLock(lock_path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema := newProtoSchema(lock)
+		if err := schema.addSheet(sheet); err != nil {
+			t.Fatal(err)
+		}
+		if err := lock.save(lock_path); err != nil {
+			t.Fatal(err)
+		}
+		var buf strings.Builder
+		schema.generate(func(s string) { buf.WriteString(s) }, "sample")
+		return buf.String()
+	}
+
+	proto := generate("int32,string,int32", "Id,Name,Atk")
+	if !strings.Contains(proto, "message S_Item {\n\tint32 Id = 1;\n\tstring Name = 2;\n\tint32 Atk = 3;\n}\n") {
+		t.Fatalf("first proto:\n%s", proto)
+	}
+	//删除 Name,调整列顺序并新增 Def,已有字段编号不变,Name 的编号保留
+	proto = generate("int32,int32,int32", "Id,Def,Atk")
+	if !strings.Contains(proto, "message S_Item {\n\tint32 Id = 1;\n\tint32 Def = 4;\n\tint32 Atk = 3;\n\treserved 2;\n}\n") {
+		t.Fatalf("second proto:\n%s", proto)
+	}
+	lock, err := loadProtoLock(lock_path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(lock.Messages) != "map[S_Item:map[Atk:3 Def:4 Id:1 Name:2]]" {
+		t.Errorf("lock = %v", lock.Messages)
+	}
+	//恢复 Name 时使用原编号
+	proto = generate("int32,string", "Id,Name")
+	if !strings.Contains(proto, "message S_Item {\n\tint32 Id = 1;\n\tstring Name = 2;\n\treserved 3, 4;\n}\n") {
+		t.Errorf("third proto:\n%s", proto)
+	}
+}
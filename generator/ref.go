@@ -0,0 +1,107 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// 检查已解析的excel文件中的外键引用,每个不存在的主键记录到所在单元格
+// 在所有excel文件解析完成后执行,引用的标签所在文件未解析时打开该文件
+// 记录每个excel文件引用的其他excel文件,被引用的文件变化时需要重新检查
+func (g *Generator) checkRefs(workbooks map[string]*Workbook, check func(pathfile string) bool) {
+	files := make([]string, 0, len(g.Excels))
+	for pathfile := range g.Excels {
+		if check(pathfile) && workbooks[pathfile] != nil {
+			files = append(files, pathfile)
+		}
+	}
+	sort.Strings(files)
+	for _, pathfile := range files {
+		wb := workbooks[pathfile]
+		deps := make(map[string]bool)
+		sheetNames := make([]string, 0, len(wb.sheets))
+		for sheetName := range wb.sheets {
+			sheetNames = append(sheetNames, sheetName)
+		}
+		sort.Strings(sheetNames)
+		for _, sheetName := range sheetNames {
+			sheet := wb.sheets[sheetName]
+			for _, field := range sheet.Fields {
+				ref := field.Type.Leaf().Ref
+				if ref == "" {
+					continue
+				}
+				target, err := g.refSheet(wb, workbooks, ref)
+				if err != nil {
+					sheet.report(1, field, err)
+					continue
+				}
+				if target.Composite() {
+					sheet.report(1, field, fmt.Errorf("can not ref sheet %s with composite key", target.Name))
+					continue
+				}
+				if target.wb != wb {
+					deps[target.wb.Path] = true
+				}
+				for _, rowIdx := range sheet.rows {
+					if record, ok := sheet.records[rowIdx]; ok && record.Values[field.Index] != nil {
+						checkRef(sheet, rowIdx, field, target, record.Values[field.Index])
+					}
+				}
+			}
+		}
+		refs := make([]string, 0, len(deps))
+		for dep := range deps {
+			refs = append(refs, dep)
+		}
+		sort.Strings(refs)
+		g.refs[pathfile] = refs
+	}
+}
+
+// 外键引用的标签: 同一文件中的标签,或其他excel文件导出的标签
+func (g *Generator) refSheet(wb *Workbook, workbooks map[string]*Workbook, sheetName string) (*Sheet, error) {
+	if _, ok := wb.file.Sheet(sheetName); ok {
+		return wb.Sheet(sheetName)
+	}
+	for pathfile, sheetNames := range g.Excels {
+		for _, name := range sheetNames {
+			if name != sheetName {
+				continue
+			}
+			owner := workbooks[pathfile]
+			if owner == nil {
+				var err error
+				if owner, err = g.openWorkbook(pathfile); err != nil {
+					g.diag.Add(&Diagnostic{File: pathfile, Row: -1, Col: -1, Msg: err.Error()})
+					return nil, errInvalid
+				}
+				workbooks[pathfile] = owner
+			}
+			sheet, err := owner.Sheet(sheetName)
+			if err != nil {
+				owner.report(sheetName, -1, -1, "", "", err)
+				return nil, errInvalid
+			}
+			return sheet, nil
+		}
+	}
+	return nil, fmt.Errorf("no found ref sheet %s", sheetName)
+}
+
+// 检查数据中的外键引用,数组元素及 map 的值逐个检查,零值(空单元格)表示没有引用
+func checkRef(sheet *Sheet, rowIdx int, field *Field, target *Sheet, v *Value) {
+	if v.Type.Ref != "" {
+		if _, ok := target.index[v.String()]; !ok && !v.IsZero() {
+			sheet.report(rowIdx, field, fmt.Errorf("no found ref key %q in sheet %s", v.String(), target.Name))
+		}
+		return
+	}
+	for _, elem := range v.List {
+		checkRef(sheet, rowIdx, field, target, elem)
+	}
+}
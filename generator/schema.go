@@ -0,0 +1,478 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// 表头所占行数: 第一行字段注释 第二行字段数据类型 第三行字段名
+const HEAD_ROWS = 3
+
+// excel 文件(或 csv 目录),缓存已解析的标签结构及数据
+type Workbook struct {
+	Path string
+
+	g      *Generator
+	file   Source
+	sheets map[string]*Sheet
+	//表头存在错误的标签
+	failed map[string]bool
+	//已解析的枚举,存在错误的枚举为 nil
+	enums map[string]*Enum
+}
+
+// 字段定义
+type Field struct {
+	//在 Sheet.Fields 中的位置
+	Index int
+	//所在列
+	Col  int
+	Name string
+	Desc string
+	Type *Type
+	//字段约束,没有约束时为 nil
+	Constraints *Constraints
+	//空单元格使用的默认值,为空时使用零值
+	Default string
+}
+
+// 是否为可选字段,空单元格的值为 nil(未设置)
+func (f *Field) Optional() bool {
+	return f.Constraints != nil && f.Constraints.Optional
+}
+
+// 标签结构定义及数据
+type Sheet struct {
+	Name   string
+	Fields []*Field
+	//主键字段,组合主键时为第一个主键字段
+	Key *Field
+	//所有主键字段,按列顺序,多于一个时为组合主键
+	Keys []*Field
+
+	wb  *Workbook
+	raw SourceSheet
+	//主键 -> 行号,组合主键以 keySeparator 连接
+	index map[string]int
+	//数据行号,按表格顺序
+	rows []int
+	//已解析的数据行
+	records map[int]*Record
+	//正在解析的数据行,用于检查循环引用
+	decoding map[int]bool
+	//存在错误的数据行
+	failed map[int]bool
+	//unique 约束及 index 已出现的值 key=字段位置 value=值 -> 行号
+	unique map[int]map[string]int
+}
+
+// 标签中的一行数据
+type Record struct {
+	Sheet *Sheet
+	//行号,从 0 开始
+	Row int
+	//与 Sheet.Fields 一一对应,未设置的可选字段为 nil
+	Values []*Value
+}
+
+// 组合主键索引中各主键值的分隔符
+const keySeparator = "\x00"
+
+// 主键值,组合主键时为第一个主键的值
+func (r *Record) Key() *Value {
+	return r.Values[r.Sheet.Key.Index]
+}
+
+// 主键的文本形式,组合主键以 , 连接 eg: 1001,3
+func (r *Record) KeyString() string {
+	keys := make([]string, 0, len(r.Sheet.Keys))
+	for _, key := range r.Sheet.Keys {
+		keys = append(keys, r.Values[key.Index].String())
+	}
+	return strings.Join(keys, ",")
+}
+
+// 是否为组合主键
+func (s *Sheet) Composite() bool {
+	return len(s.Keys) > 1
+}
+
+// 标记 index 或 group 的索引字段
+func (s *Sheet) Indexes() []*Field {
+	indexes := make([]*Field, 0)
+	for _, field := range s.Fields {
+		if c := field.Constraints; c != nil && (c.Index || c.Group) {
+			indexes = append(indexes, field)
+		}
+	}
+	return indexes
+}
+
+// 按主键逐层分组的数据,单主键只有一层
+type keyNode struct {
+	Key *Value
+	//最后一层主键对应的数据行
+	Record *Record
+	//下一层主键
+	Children []*keyNode
+
+	records []*Record
+}
+
+// 按第 depth 个主键分组,保持表格顺序 eg: S_X[hero][level]
+func keyTree(records []*Record, depth int) []*keyNode {
+	nodes := make([]*keyNode, 0)
+	if len(records) == 0 {
+		return nodes
+	}
+	keys := records[0].Sheet.Keys
+	index := make(map[string]*keyNode)
+	for _, record := range records {
+		key := record.Values[keys[depth].Index]
+		node, ok := index[key.String()]
+		if !ok {
+			node = &keyNode{Key: key}
+			index[key.String()] = node
+			nodes = append(nodes, node)
+		}
+		if depth == len(keys)-1 {
+			node.Record = record
+		} else {
+			node.records = append(node.records, record)
+		}
+	}
+	if depth < len(keys)-1 {
+		for _, node := range nodes {
+			node.Children = keyTree(node.records, depth+1)
+			node.records = nil
+		}
+	}
+	return nodes
+}
+
+func (g *Generator) openWorkbook(pathfile string) (*Workbook, error) {
+	file, err := OpenSource(pathfile)
+	if err != nil {
+		return nil, err
+	}
+	return &Workbook{
+		Path:   pathfile,
+		g:      g,
+		file:   file,
+		sheets: make(map[string]*Sheet),
+		failed: make(map[string]bool),
+		enums:  make(map[string]*Enum),
+	}, nil
+}
+
+// 记录错误,errInvalid 表示错误已记录过
+func (wb *Workbook) report(sheetName string, row, col int, column, typ string, err error) {
+	if err == nil || errors.Is(err, errInvalid) {
+		return
+	}
+	wb.g.diag.Add(&Diagnostic{
+		File:   wb.Path,
+		Sheet:  sheetName,
+		Row:    row,
+		Col:    col,
+		Column: column,
+		Type:   typ,
+		Msg:    err.Error(),
+	})
+}
+
+// 记录字段所在单元格的错误
+func (s *Sheet) report(row int, field *Field, err error) {
+	s.wb.report(s.Name, row, field.Col, field.Name, field.Type.String(), err)
+}
+
+// 获取标签结构定义,引用的子标签一并解析
+// 表头存在错误时错误已记录,返回 errInvalid
+func (wb *Workbook) Sheet(sheetName string) (*Sheet, error) {
+	if sheet, ok := wb.sheets[sheetName]; ok {
+		return sheet, nil
+	}
+	if wb.failed[sheetName] {
+		return nil, errInvalid
+	}
+	sheet_root, ok := wb.file.Sheet(sheetName)
+	if !ok {
+		return nil, fmt.Errorf("no sheet %s available", sheetName)
+	}
+	sheet := &Sheet{
+		Name:     sheetName,
+		wb:       wb,
+		raw:      sheet_root,
+		records:  make(map[int]*Record),
+		decoding: make(map[int]bool),
+		failed:   make(map[int]bool),
+	}
+	//先缓存再解析,允许标签之间循环引用
+	wb.sheets[sheetName] = sheet
+	ok = sheet.parseHead()
+	if ok {
+		for _, field := range sheet.Fields {
+			if leaf := field.Type.Leaf(); leaf.Kind == TypeStruct {
+				if son, err := wb.Sheet(leaf.Name); err != nil {
+					sheet.report(1, field, err)
+					ok = false
+				} else if son.Composite() {
+					sheet.report(1, field, fmt.Errorf("can not reference sheet %s with composite key", son.Name))
+					ok = false
+				}
+			}
+			for _, enumName := range field.Type.enums() {
+				if _, err := wb.Enum(enumName); err != nil {
+					sheet.report(1, field, err)
+					ok = false
+				}
+			}
+		}
+	}
+	if !ok {
+		delete(wb.sheets, sheetName)
+		wb.failed[sheetName] = true
+		return nil, errInvalid
+	}
+	sheet.buildIndex()
+	return sheet, nil
+}
+
+func cellValue(sheet SourceSheet, row, col int) (string, error) {
+	return sheet.Cell(row, col)
+}
+
+// 解析表头,所有错误记录后返回是否成功
+func (s *Sheet) parseHead() bool {
+	if s.raw.MaxRow() < HEAD_ROWS {
+		s.wb.report(s.Name, -1, -1, "", "", fmt.Errorf("invalid head,need %d head rows", HEAD_ROWS))
+		return false
+	}
+	ok := true
+	hash := make(map[string]bool)
+	for i := 0; i < s.raw.MaxCol(2); i++ {
+		att_name, err := cellValue(s.raw, 2, i)
+		if err != nil {
+			s.wb.report(s.Name, 2, i, "", "", err)
+			ok = false
+			continue
+		}
+		att_name = strings.TrimSpace(att_name)
+		att_type, err := cellValue(s.raw, 1, i)
+		if err != nil {
+			s.wb.report(s.Name, 1, i, att_name, "", err)
+			ok = false
+			continue
+		}
+		att_type = strings.TrimSpace(att_type)
+		att_desc, err := cellValue(s.raw, 0, i)
+		if err != nil {
+			s.wb.report(s.Name, 0, i, att_name, att_type, err)
+			ok = false
+			continue
+		}
+		att_desc = strings.TrimSpace(att_desc)
+
+		if r, _ := utf8.DecodeRuneInString(att_type); r == '!' {
+			continue
+		}
+		if att_name == "" && att_type == "" {
+			continue
+		}
+		if att_name == "" {
+			s.wb.report(s.Name, 2, i, "", att_type, fmt.Errorf("empty field name"))
+			ok = false
+			continue
+		}
+		if hash[att_name] {
+			s.wb.report(s.Name, 2, i, att_name, att_type, fmt.Errorf("duplicate field name in struct literal"))
+			ok = false
+			continue
+		}
+		hash[att_name] = true
+		t, def, rest, err := parseColumnType(att_type)
+		if err != nil {
+			s.wb.report(s.Name, 1, i, att_name, att_type, err)
+			ok = false
+			continue
+		}
+		constraints, err := s.wb.parseConstraints(t, rest)
+		if err != nil {
+			s.wb.report(s.Name, 1, i, att_name, att_type, err)
+			ok = false
+			continue
+		}
+		field := &Field{
+			Index:       len(s.Fields),
+			Col:         i,
+			Name:        att_name,
+			Desc:        att_desc,
+			Type:        t,
+			Constraints: constraints,
+			Default:     def,
+		}
+		if err := s.checkDefault(field); err != nil {
+			s.wb.report(s.Name, 1, i, att_name, att_type, err)
+			ok = false
+			continue
+		}
+		s.Fields = append(s.Fields, field)
+	}
+	if !ok {
+		return false
+	}
+	//标记 key 的字段为主键,没有标记时第一列为主键
+	for _, field := range s.Fields {
+		if field.Constraints != nil && field.Constraints.Key {
+			s.Keys = append(s.Keys, field)
+		}
+	}
+	if len(s.Keys) == 0 {
+		if len(s.Fields) == 0 || s.Fields[0].Col != MAINKEY_INDEX {
+			s.wb.report(s.Name, 2, MAINKEY_INDEX, "", "", fmt.Errorf("no found main key field"))
+			return false
+		}
+		s.Keys = []*Field{s.Fields[0]}
+	}
+	s.Key = s.Keys[0]
+	for _, key := range s.Keys {
+		if key.Type.Kind != TypeScalar {
+			s.report(1, key, fmt.Errorf("main key must be base type"))
+			ok = false
+			continue
+		}
+		if key.Optional() || key.Default != "" {
+			s.report(1, key, fmt.Errorf("main key can not be optional or have default value"))
+			ok = false
+		}
+	}
+	return ok
+}
+
+// 建立主键索引,主键为空的行忽略,主键错误的行记录错误后忽略
+// 组合主键所有主键为空的行忽略,部分为空时记录错误
+func (s *Sheet) buildIndex() {
+	s.index = make(map[string]int)
+	for rowIdx := HEAD_ROWS; rowIdx < s.raw.MaxRow(); rowIdx++ {
+		mkvalues := make([]string, 0, len(s.Keys))
+		for _, key := range s.Keys {
+			mkvalue, err := cellValue(s.raw, rowIdx, key.Col)
+			if err != nil {
+				s.report(rowIdx, key, fmt.Errorf("invalid main key value,err:%v", err))
+				break
+			}
+			mkvalues = append(mkvalues, strings.TrimSpace(mkvalue))
+		}
+		if len(mkvalues) != len(s.Keys) || strings.Join(mkvalues, "") == "" {
+			continue
+		}
+		keys := make([]string, 0, len(s.Keys))
+		for i, key := range s.Keys {
+			if mkvalues[i] == "" {
+				s.report(rowIdx, key, fmt.Errorf("empty main key value"))
+				break
+			}
+			v, err := s.wb.decodeScalar(key.Type, mkvalues[i])
+			if err != nil {
+				s.report(rowIdx, key, fmt.Errorf("invalid main key value,err:%v", err))
+				break
+			}
+			keys = append(keys, v.String())
+		}
+		if len(keys) != len(s.Keys) {
+			continue
+		}
+		index := strings.Join(keys, keySeparator)
+		if pre, ok := s.index[index]; ok {
+			s.report(rowIdx, s.Key, fmt.Errorf("duplicate main key's value %q, first defined at %s", strings.Join(mkvalues, ","), CellName(pre, s.Key.Col)))
+			continue
+		}
+		s.index[index] = rowIdx
+		s.rows = append(s.rows, rowIdx)
+	}
+}
+
+// 按表格顺序解析所有数据行,存在错误的行记录错误后跳过并返回 errInvalid
+func (s *Sheet) Records() ([]*Record, error) {
+	records := make([]*Record, 0, len(s.rows))
+	var rerr error
+	for _, rowIdx := range s.rows {
+		record, err := s.record(rowIdx)
+		if err != nil {
+			rerr = errInvalid
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, rerr
+}
+
+// 根据主键查找数据行,组合主键的标签不能引用
+func (s *Sheet) Lookup(value string) (*Record, error) {
+	if s.Composite() {
+		return nil, fmt.Errorf("can not reference sheet %s with composite key", s.Name)
+	}
+	key, err := s.wb.decodeScalar(s.Key.Type, strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key of sheet %s,err:%v", s.Name, err)
+	}
+	rowIdx, ok := s.index[key.String()]
+	if !ok {
+		return nil, fmt.Errorf("no found key %q in sheet %s", value, s.Name)
+	}
+	return s.record(rowIdx)
+}
+
+// 解析一行数据,每个字段的错误都会记录,存在错误时返回 errInvalid
+func (s *Sheet) record(rowIdx int) (*Record, error) {
+	if record, ok := s.records[rowIdx]; ok {
+		return record, nil
+	}
+	if s.failed[rowIdx] {
+		return nil, errInvalid
+	}
+	if s.decoding[rowIdx] {
+		return nil, fmt.Errorf("circular reference to sheet %s row %d", s.Name, rowIdx+1)
+	}
+	s.decoding[rowIdx] = true
+	defer delete(s.decoding, rowIdx)
+	record := &Record{Sheet: s, Row: rowIdx, Values: make([]*Value, len(s.Fields))}
+	ok := true
+	for i, field := range s.Fields {
+		att_value, err := cellValue(s.raw, rowIdx, field.Col)
+		if err == nil {
+			switch {
+			case strings.TrimSpace(att_value) == "" && field.Optional():
+				//可选字段未设置
+				continue
+			case strings.TrimSpace(att_value) == "" && field.Default != "":
+				record.Values[i], err = s.wb.decodeDefault(field)
+			default:
+				record.Values[i], err = s.wb.decode(field.Type, att_value, false)
+			}
+		}
+		if err != nil {
+			s.report(rowIdx, field, err)
+			ok = false
+			continue
+		}
+		if field.Constraints != nil {
+			for _, err := range s.checkConstraints(rowIdx, field, att_value, record.Values[i]) {
+				s.report(rowIdx, field, err)
+				ok = false
+			}
+		}
+	}
+	if !ok {
+		s.failed[rowIdx] = true
+		return nil, errInvalid
+	}
+	s.records[rowIdx] = record
+	return record, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// 表格数据源(excel 文件、csv 目录等),由多个标签组成
+type Source interface {
+	//按表格顺序的所有标签
+	Sheets() []SourceSheet
+	//根据标签名获取标签
+	Sheet(name string) (SourceSheet, bool)
+}
+
+// 数据源中的一个标签
+type SourceSheet interface {
+	Name() string
+	//行数
+	MaxRow() int
+	//指定行的列数
+	MaxCol(row int) int
+	//单元格内容,超出范围时返回空字符串,行列从 0 开始
+	Cell(row, col int) (string, error)
+}
+
+// 数据源打开函数
+type SourceOpener func(pathfile string) (Source, error)
+
+var (
+	//文件扩展名 -> 数据源打开函数
+	sourceOpeners = make(map[string]SourceOpener)
+	//一个文件对应一个标签的扩展名,同一目录下的文件组成一个数据源
+	sheetFileExts = make(map[string]bool)
+)
+
+// 注册文件格式,sheetFile 为 true 时一个文件即一个标签,同一目录下的文件组成一个数据源
+func RegisterSource(ext string, sheetFile bool, opener SourceOpener) {
+	ext = strings.ToLower(ext)
+	sourceOpeners[ext] = opener
+	workbookExts[ext] = true
+	if sheetFile {
+		sheetFileExts[ext] = true
+	}
+}
+
+// 是否是一个文件即一个标签的格式(csv、tsv)
+func isSheetFile(pathfile string) bool {
+	return sheetFileExts[strings.ToLower(path.Ext(pathfile))]
+}
+
+// 打开数据源,目录作为 csv、tsv 等单标签文件的集合
+func OpenSource(pathfile string) (Source, error) {
+	info, err := os.Stat(pathfile)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return openSheetDir(pathfile)
+	}
+	opener, ok := sourceOpeners[strings.ToLower(filepath.Ext(pathfile))]
+	if !ok {
+		return nil, fmt.Errorf("unsupported file format %s", pathfile)
+	}
+	return opener(pathfile)
+}
+
+// 已读入内存的标签集合
+type sheetList struct {
+	sheets []SourceSheet
+	index  map[string]SourceSheet
+}
+
+func newSheetList() *sheetList {
+	return &sheetList{index: make(map[string]SourceSheet)}
+}
+
+func (s *sheetList) add(sheet SourceSheet) error {
+	if _, ok := s.index[sheet.Name()]; ok {
+		return fmt.Errorf("duplicate sheet:%s", sheet.Name())
+	}
+	s.index[sheet.Name()] = sheet
+	s.sheets = append(s.sheets, sheet)
+	return nil
+}
+
+func (s *sheetList) Sheets() []SourceSheet {
+	return s.sheets
+}
+
+func (s *sheetList) Sheet(name string) (SourceSheet, bool) {
+	sheet, ok := s.index[name]
+	return sheet, ok
+}
+
+// 目录中的单标签文件组成的数据源,标签名为文件名
+func openSheetDir(dir string) (Source, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	src := newSheetList()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, "~$") || !isSheetFile(name) {
+			continue
+		}
+		sub, err := sourceOpeners[strings.ToLower(path.Ext(name))](filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, sheet := range sub.Sheets() {
+			if err := src.add(sheet); err != nil {
+				return nil, fmt.Errorf("%v in %s", err, dir)
+			}
+		}
+	}
+	return src, nil
+}
+
+// 按行保存单元格内容的标签(csv、ods)
+type rowsSheet struct {
+	name string
+	rows [][]string
+}
+
+func (s *rowsSheet) Name() string {
+	return s.name
+}
+
+func (s *rowsSheet) MaxRow() int {
+	return len(s.rows)
+}
+
+func (s *rowsSheet) MaxCol(row int) int {
+	if row < 0 || row >= len(s.rows) {
+		return 0
+	}
+	return len(s.rows[row])
+}
+
+func (s *rowsSheet) Cell(row, col int) (string, error) {
+	if col < 0 || col >= s.MaxCol(row) {
+		return "", nil
+	}
+	return s.rows[row][col], nil
+}
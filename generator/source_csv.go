@@ -0,0 +1,44 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+func init() {
+	RegisterSource(".csv", true, func(pathfile string) (Source, error) {
+		return openCSV(pathfile, ',')
+	})
+	RegisterSource(".tsv", true, func(pathfile string) (Source, error) {
+		return openCSV(pathfile, '\t')
+	})
+}
+
+// csv、tsv 文件,一个文件即一个标签,标签名为文件名
+func openCSV(pathfile string, comma rune) (Source, error) {
+	data, err := ioutil.ReadFile(pathfile)
+	if err != nil {
+		return nil, err
+	}
+	//excel 导出的 csv 带有 utf-8 BOM
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = comma
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	name := path.Base(CleanPath(pathfile))
+	src := newSheetList()
+	src.add(&rowsSheet{name: strings.TrimSuffix(name, path.Ext(name)), rows: rows})
+	return src, nil
+}
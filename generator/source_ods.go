@@ -0,0 +1,243 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterSource(".ods", false, openODS)
+}
+
+const (
+	odsTableNS  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odsOfficeNS = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	odsTextNS   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+// OpenDocument 表格文件(LibreOffice .ods),解析 content.xml
+// 末尾重复的空行、空单元格(number-rows-repeated、number-columns-repeated)不展开
+func openODS(pathfile string) (Source, error) {
+	zr, err := zip.OpenReader(pathfile)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if f.Name != "content.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		src := newSheetList()
+		if err := parseODS(src, xml.NewDecoder(rc)); err != nil {
+			return nil, fmt.Errorf("invalid ods file %s,err:%v", pathfile, err)
+		}
+		return src, nil
+	}
+	return nil, fmt.Errorf("invalid ods file %s,err:no content.xml", pathfile)
+}
+
+func odsAttr(se xml.StartElement, space, local string) string {
+	for _, attr := range se.Attr {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// 重复次数,未设置时为 1
+func odsRepeated(se xml.StartElement, local string) (int, error) {
+	s := odsAttr(se, odsTableNS, local)
+	if s == "" {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid %s %q", local, s)
+	}
+	return n, nil
+}
+
+// 解析 content.xml 中的所有 table:table
+func parseODS(src *sheetList, d *xml.Decoder) error {
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Space != odsTableNS || se.Name.Local != "table" {
+			continue
+		}
+		sheet := &rowsSheet{name: odsAttr(se, odsTableNS, "name")}
+		if err := parseODSTable(sheet, d); err != nil {
+			return fmt.Errorf("sheet %s,%v", sheet.name, err)
+		}
+		if err := src.add(sheet); err != nil {
+			return err
+		}
+	}
+}
+
+// 解析 table:table 直到结束标签,行可能位于 table:table-row-group、table:table-header-rows 中
+func parseODSTable(s *rowsSheet, d *xml.Decoder) error {
+	//尚未展开的空行数
+	pending := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			if t.Name.Space == odsTableNS && t.Name.Local == "table" {
+				return nil
+			}
+		case xml.StartElement:
+			if t.Name.Space != odsTableNS || t.Name.Local != "table-row" {
+				continue
+			}
+			repeated, err := odsRepeated(t, "number-rows-repeated")
+			if err != nil {
+				return err
+			}
+			cells, err := parseODSRow(d)
+			if err != nil {
+				return err
+			}
+			if len(cells) == 0 {
+				pending += repeated
+				continue
+			}
+			for ; pending > 0; pending-- {
+				s.rows = append(s.rows, nil)
+			}
+			for i := 0; i < repeated; i++ {
+				s.rows = append(s.rows, cells)
+			}
+		}
+	}
+}
+
+// 解析 table:table-row 直到结束标签,返回去掉末尾空单元格的内容
+func parseODSRow(d *xml.Decoder) ([]string, error) {
+	cells := make([]string, 0)
+	//尚未展开的空单元格数
+	pending := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			if t.Name.Space == odsTableNS && t.Name.Local == "table-row" {
+				return cells, nil
+			}
+		case xml.StartElement:
+			if t.Name.Space != odsTableNS || (t.Name.Local != "table-cell" && t.Name.Local != "covered-table-cell") {
+				continue
+			}
+			repeated, err := odsRepeated(t, "number-columns-repeated")
+			if err != nil {
+				return nil, err
+			}
+			value, err := parseODSCell(d, t)
+			if err != nil {
+				return nil, err
+			}
+			if value == "" {
+				pending += repeated
+				continue
+			}
+			for ; pending > 0; pending-- {
+				cells = append(cells, "")
+			}
+			for i := 0; i < repeated; i++ {
+				cells = append(cells, value)
+			}
+		}
+	}
+}
+
+// 解析单元格直到结束标签
+// 数值使用 office:value 保留完整精度,其他类型使用显示的文本,多个段落以换行连接
+func parseODSCell(d *xml.Decoder, se xml.StartElement) (string, error) {
+	var buf strings.Builder
+	paragraphs := 0
+	//所在的 text:p 层数,段落外的文本(如批注中的)忽略
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			if t.Name == se.Name {
+				switch odsAttr(se, odsOfficeNS, "value-type") {
+				case "float", "percentage", "currency":
+					if v := odsAttr(se, odsOfficeNS, "value"); v != "" {
+						return v, nil
+					}
+				}
+				return buf.String(), nil
+			}
+			if t.Name.Space == odsTextNS && (t.Name.Local == "p" || t.Name.Local == "h") {
+				depth--
+			}
+		case xml.StartElement:
+			if t.Name.Space == odsOfficeNS && t.Name.Local == "annotation" {
+				//批注不属于单元格内容
+				if err := d.Skip(); err != nil {
+					return "", err
+				}
+				continue
+			}
+			if t.Name.Space != odsTextNS {
+				continue
+			}
+			switch t.Name.Local {
+			case "p", "h":
+				if paragraphs > 0 {
+					buf.WriteString("\n")
+				}
+				paragraphs++
+				depth++
+			case "s":
+				//连续空格 text:s text:c="n"
+				n := 1
+				if c := odsAttr(t, odsTextNS, "c"); c != "" {
+					if v, err := strconv.Atoi(c); err == nil && v > 0 {
+						n = v
+					}
+				}
+				buf.WriteString(strings.Repeat(" ", n))
+			case "tab":
+				buf.WriteString("\t")
+			case "line-break":
+				buf.WriteString("\n")
+			}
+		case xml.CharData:
+			if depth > 0 {
+				buf.Write(t)
+			}
+		}
+	}
+}
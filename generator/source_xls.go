@@ -0,0 +1,596 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+func init() {
+	RegisterSource(".xls", false, openXLS)
+}
+
+// BIFF8 记录类型
+const (
+	biffFormula    = 0x0006
+	biffEOF        = 0x000A
+	biffDateMode   = 0x0022
+	biffFilePass   = 0x002F
+	biffContinue   = 0x003C
+	biffBoundSheet = 0x0085
+	biffMulRK      = 0x00BD
+	biffXF         = 0x00E0
+	biffSST        = 0x00FC
+	biffLabelSST   = 0x00FD
+	biffNumber     = 0x0203
+	biffLabel      = 0x0204
+	biffBoolErr    = 0x0205
+	biffString     = 0x0207
+	biffArray      = 0x0221
+	biffTable      = 0x0236
+	biffRK         = 0x027E
+	biffFormat     = 0x041E
+	biffShrFmla    = 0x04BC
+	biffBOF        = 0x0809
+)
+
+// 错误值
+var biffErrors = map[byte]string{
+	0x00: "#NULL!",
+	0x07: "#DIV/0!",
+	0x0F: "#VALUE!",
+	0x17: "#REF!",
+	0x1D: "#NAME?",
+	0x24: "#NUM!",
+	0x2A: "#N/A",
+}
+
+// Excel 97-2003 文件(.xls),Compound File 中的 Workbook 流为 BIFF8 格式
+func openXLS(pathfile string) (Source, error) {
+	data, err := ioutil.ReadFile(pathfile)
+	if err != nil {
+		return nil, err
+	}
+	src, err := parseXLS(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xls file %s,err:%v", pathfile, err)
+	}
+	return src, nil
+}
+
+func parseXLS(data []byte) (Source, error) {
+	cfb, err := openCFB(data)
+	if err != nil {
+		return nil, err
+	}
+	stream, ok, err := cfb.stream("Workbook")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		//BIFF5 及更早版本的流名为 Book
+		if _, ok, _ := cfb.stream("Book"); ok {
+			return nil, fmt.Errorf("unsupported BIFF version, save as Excel 97-2003 or xlsx")
+		}
+		return nil, fmt.Errorf("no Workbook stream")
+	}
+	book := &xlsBook{stream: stream, formats: make(map[uint16]string)}
+	return book.parse()
+}
+
+// 一条 BIFF 记录,后续的 CONTINUE 记录一并读入
+type biffRecord struct {
+	typ  uint16
+	segs [][]byte
+	//当前读取位置
+	seg, pos int
+}
+
+// 读取 off 处的记录,返回下一条记录的位置
+func readBiffRecord(stream []byte, off int) (*biffRecord, int, error) {
+	rec := &biffRecord{}
+	for first := true; off+4 <= len(stream); first = false {
+		typ := binary.LittleEndian.Uint16(stream[off:])
+		size := int(binary.LittleEndian.Uint16(stream[off+2:]))
+		if !first && typ != biffContinue {
+			break
+		}
+		if off+4+size > len(stream) {
+			return nil, 0, fmt.Errorf("truncated record 0x%04x at %d", typ, off)
+		}
+		if first {
+			rec.typ = typ
+		}
+		rec.segs = append(rec.segs, stream[off+4:off+4+size])
+		off += 4 + size
+	}
+	if len(rec.segs) == 0 {
+		return nil, 0, fmt.Errorf("unexpected end of stream")
+	}
+	return rec, off, nil
+}
+
+// 记录主体(不含 CONTINUE)
+func (r *biffRecord) data() []byte {
+	return r.segs[0]
+}
+
+// 读取 n 个字节,可以跨越 CONTINUE 记录
+func (r *biffRecord) read(n int) ([]byte, error) {
+	buf := make([]byte, 0, n)
+	for len(buf) < n {
+		if r.seg >= len(r.segs) {
+			return nil, fmt.Errorf("unexpected end of record 0x%04x", r.typ)
+		}
+		seg := r.segs[r.seg]
+		m := n - len(buf)
+		if m > len(seg)-r.pos {
+			m = len(seg) - r.pos
+		}
+		buf = append(buf, seg[r.pos:r.pos+m]...)
+		r.pos += m
+		if r.pos >= len(seg) {
+			r.seg++
+			r.pos = 0
+		}
+	}
+	return buf, nil
+}
+
+func (r *biffRecord) u8() (int, error) {
+	bs, err := r.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return int(bs[0]), nil
+}
+
+func (r *biffRecord) u16() (int, error) {
+	bs, err := r.read(2)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.LittleEndian.Uint16(bs)), nil
+}
+
+func (r *biffRecord) u32() (int, error) {
+	bs, err := r.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.LittleEndian.Uint32(bs)), nil
+}
+
+// 读取 cch 个字符的 XLUnicodeRichExtendedString(不含 cch)
+// 字符跨越 CONTINUE 记录时,新记录的第一个字节为该部分字符的压缩标志
+func (r *biffRecord) unicode(cch int) (string, error) {
+	flags, err := r.u8()
+	if err != nil {
+		return "", err
+	}
+	runs, ext := 0, 0
+	if flags&0x08 != 0 {
+		if runs, err = r.u16(); err != nil {
+			return "", err
+		}
+	}
+	if flags&0x04 != 0 {
+		if ext, err = r.u32(); err != nil {
+			return "", err
+		}
+	}
+	units := make([]uint16, 0, cch)
+	high := flags&0x01 != 0
+	for len(units) < cch {
+		//表头已读取,位于新记录开头说明跨越了 CONTINUE 记录
+		if r.seg < len(r.segs) && r.seg > 0 && r.pos == 0 {
+			b, err := r.u8()
+			if err != nil {
+				return "", err
+			}
+			high = b&0x01 != 0
+		}
+		if r.seg >= len(r.segs) {
+			return "", fmt.Errorf("unexpected end of record 0x%04x", r.typ)
+		}
+		size := 1
+		if high {
+			size = 2
+		}
+		//只读取当前记录中的字符
+		n := (len(r.segs[r.seg]) - r.pos) / size
+		if n > cch-len(units) {
+			n = cch - len(units)
+		}
+		if n == 0 {
+			return "", fmt.Errorf("invalid string in record 0x%04x", r.typ)
+		}
+		bs, err := r.read(n * size)
+		if err != nil {
+			return "", err
+		}
+		for i := 0; i < n; i++ {
+			if high {
+				units = append(units, binary.LittleEndian.Uint16(bs[i*2:]))
+			} else {
+				units = append(units, uint16(bs[i]))
+			}
+		}
+	}
+	//忽略格式信息
+	if _, err := r.read(runs*4 + ext); err != nil {
+		return "", err
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+type xlsBook struct {
+	stream []byte
+	//共享字符串表
+	sst []string
+	//XF 序号 -> 数字格式编号
+	xfs []uint16
+	//自定义数字格式
+	formats  map[uint16]string
+	date1904 bool
+}
+
+// 标签信息
+type xlsBoundSheet struct {
+	name   string
+	offset int
+	//0 为工作表,其他为图表、宏表等
+	typ byte
+}
+
+// 解析 workbook globals,再逐个解析工作表
+func (b *xlsBook) parse() (Source, error) {
+	rec, off, err := readBiffRecord(b.stream, 0)
+	if err != nil {
+		return nil, err
+	}
+	if rec.typ != biffBOF || len(rec.data()) < 4 {
+		return nil, fmt.Errorf("missing BOF record")
+	}
+	if v := binary.LittleEndian.Uint16(rec.data()); v != 0x0600 {
+		return nil, fmt.Errorf("unsupported BIFF version 0x%04x, save as Excel 97-2003 or xlsx", v)
+	}
+	bounds := make([]xlsBoundSheet, 0)
+	for rec.typ != biffEOF {
+		if rec, off, err = readBiffRecord(b.stream, off); err != nil {
+			return nil, err
+		}
+		data := rec.data()
+		switch rec.typ {
+		case biffFilePass:
+			return nil, fmt.Errorf("encrypted workbook is not supported")
+		case biffDateMode:
+			b.date1904 = len(data) >= 2 && binary.LittleEndian.Uint16(data) == 1
+		case biffXF:
+			if len(data) < 4 {
+				return nil, fmt.Errorf("invalid XF record")
+			}
+			b.xfs = append(b.xfs, binary.LittleEndian.Uint16(data[2:]))
+		case biffFormat:
+			if len(data) < 4 {
+				return nil, fmt.Errorf("invalid FORMAT record")
+			}
+			rec.read(2)
+			cch, _ := rec.u16()
+			s, err := rec.unicode(cch)
+			if err != nil {
+				return nil, err
+			}
+			b.formats[binary.LittleEndian.Uint16(data)] = s
+		case biffBoundSheet:
+			if len(data) < 8 {
+				return nil, fmt.Errorf("invalid BOUNDSHEET record")
+			}
+			rec.read(6)
+			cch, _ := rec.u8()
+			name, err := rec.unicode(cch)
+			if err != nil {
+				return nil, err
+			}
+			bounds = append(bounds, xlsBoundSheet{name: name, offset: int(binary.LittleEndian.Uint32(data)), typ: data[5]})
+		case biffSST:
+			if err := b.parseSST(rec); err != nil {
+				return nil, err
+			}
+		}
+	}
+	src := newSheetList()
+	for _, bound := range bounds {
+		if bound.typ != 0 {
+			continue
+		}
+		sheet := &rowsSheet{name: bound.name}
+		if err := b.parseSheet(sheet, bound.offset); err != nil {
+			return nil, fmt.Errorf("sheet %s,%v", bound.name, err)
+		}
+		if err := src.add(sheet); err != nil {
+			return nil, err
+		}
+	}
+	return src, nil
+}
+
+func (b *xlsBook) parseSST(rec *biffRecord) error {
+	if _, err := rec.read(4); err != nil {
+		return err
+	}
+	unique, err := rec.u32()
+	if err != nil {
+		return err
+	}
+	//unique 来自文件,不能用作容量,数据不足时读取字符串会出错
+	b.sst = make([]string, 0)
+	for i := 0; i < unique; i++ {
+		cch, err := rec.u16()
+		if err != nil {
+			return err
+		}
+		s, err := rec.unicode(cch)
+		if err != nil {
+			return err
+		}
+		b.sst = append(b.sst, s)
+	}
+	return nil
+}
+
+// 解析 offset 处开始的工作表子流
+func (b *xlsBook) parseSheet(sheet *rowsSheet, offset int) error {
+	if offset < 0 || offset >= len(b.stream) {
+		return fmt.Errorf("invalid sheet offset %d", offset)
+	}
+	rec, off, err := readBiffRecord(b.stream, offset)
+	if err != nil {
+		return err
+	}
+	if rec.typ != biffBOF {
+		return fmt.Errorf("missing BOF record")
+	}
+	//字符串公式的结果在其后的 STRING 记录中
+	formulaRow, formulaCol := -1, -1
+	//嵌入的图表等子流
+	depth := 1
+	for depth > 0 {
+		if rec, off, err = readBiffRecord(b.stream, off); err != nil {
+			return err
+		}
+		data := rec.data()
+		switch rec.typ {
+		case biffString, biffShrFmla, biffArray, biffTable:
+		default:
+			formulaRow, formulaCol = -1, -1
+		}
+		switch rec.typ {
+		case biffBOF:
+			depth++
+			continue
+		case biffEOF:
+			depth--
+			continue
+		}
+		if depth > 1 {
+			continue
+		}
+		switch rec.typ {
+		case biffLabelSST:
+			if len(data) < 10 {
+				return fmt.Errorf("invalid LABELSST record")
+			}
+			isst := int(binary.LittleEndian.Uint32(data[6:]))
+			if isst >= len(b.sst) {
+				return fmt.Errorf("invalid shared string index %d", isst)
+			}
+			setXLSCell(sheet, data, b.sst[isst])
+		case biffLabel:
+			if len(data) < 8 {
+				return fmt.Errorf("invalid LABEL record")
+			}
+			rec.read(6)
+			cch, _ := rec.u16()
+			s, err := rec.unicode(cch)
+			if err != nil {
+				return err
+			}
+			setXLSCell(sheet, data, s)
+		case biffNumber:
+			if len(data) < 14 {
+				return fmt.Errorf("invalid NUMBER record")
+			}
+			f := math.Float64frombits(binary.LittleEndian.Uint64(data[6:]))
+			setXLSCell(sheet, data, b.number(binary.LittleEndian.Uint16(data[4:]), f))
+		case biffRK:
+			if len(data) < 10 {
+				return fmt.Errorf("invalid RK record")
+			}
+			f := xlsRK(binary.LittleEndian.Uint32(data[6:]))
+			setXLSCell(sheet, data, b.number(binary.LittleEndian.Uint16(data[4:]), f))
+		case biffMulRK:
+			if len(data) < 6 || (len(data)-6)%6 != 0 {
+				return fmt.Errorf("invalid MULRK record")
+			}
+			row := int(binary.LittleEndian.Uint16(data))
+			col := int(binary.LittleEndian.Uint16(data[2:]))
+			for i := 4; i+6 <= len(data)-2; i, col = i+6, col+1 {
+				f := xlsRK(binary.LittleEndian.Uint32(data[i+2:]))
+				setRowsCell(sheet, row, col, b.number(binary.LittleEndian.Uint16(data[i:]), f))
+			}
+		case biffBoolErr:
+			if len(data) < 8 {
+				return fmt.Errorf("invalid BOOLERR record")
+			}
+			setXLSCell(sheet, data, xlsBoolErr(data[6], data[7] != 0))
+		case biffFormula:
+			if len(data) < 14 {
+				return fmt.Errorf("invalid FORMULA record")
+			}
+			result := data[6:14]
+			if result[6] != 0xFF || result[7] != 0xFF {
+				f := math.Float64frombits(binary.LittleEndian.Uint64(result))
+				setXLSCell(sheet, data, b.number(binary.LittleEndian.Uint16(data[4:]), f))
+				continue
+			}
+			switch result[0] {
+			case 0:
+				formulaRow = int(binary.LittleEndian.Uint16(data))
+				formulaCol = int(binary.LittleEndian.Uint16(data[2:]))
+			case 1:
+				setXLSCell(sheet, data, xlsBoolErr(result[2], false))
+			case 2:
+				setXLSCell(sheet, data, xlsBoolErr(result[2], true))
+			}
+		case biffString:
+			if formulaRow < 0 {
+				continue
+			}
+			cch, err := rec.u16()
+			if err != nil {
+				return err
+			}
+			s, err := rec.unicode(cch)
+			if err != nil {
+				return err
+			}
+			setRowsCell(sheet, formulaRow, formulaCol, s)
+			formulaRow, formulaCol = -1, -1
+		}
+	}
+	return nil
+}
+
+// 单元格记录的前 4 个字节为行号、列号
+func setXLSCell(sheet *rowsSheet, data []byte, value string) {
+	setRowsCell(sheet, int(binary.LittleEndian.Uint16(data)), int(binary.LittleEndian.Uint16(data[2:])), value)
+}
+
+func setRowsCell(sheet *rowsSheet, row, col int, value string) {
+	if value == "" {
+		return
+	}
+	for len(sheet.rows) <= row {
+		sheet.rows = append(sheet.rows, nil)
+	}
+	for len(sheet.rows[row]) <= col {
+		sheet.rows[row] = append(sheet.rows[row], "")
+	}
+	sheet.rows[row][col] = value
+}
+
+// RK 压缩的数值: bit0 为是否除以 100,bit1 为是否为整数
+func xlsRK(rk uint32) float64 {
+	var f float64
+	if rk&0x02 != 0 {
+		f = float64(int32(rk) >> 2)
+	} else {
+		f = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		f /= 100
+	}
+	return f
+}
+
+func xlsBoolErr(v byte, isErr bool) string {
+	if isErr {
+		if s, ok := biffErrors[v]; ok {
+			return s
+		}
+		return "#ERR!"
+	}
+	if v != 0 {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// 数值按单元格格式输出,日期格式输出为 2006-01-02 15:04:05,其他保留完整精度
+func (b *xlsBook) number(xf uint16, f float64) string {
+	if int(xf) < len(b.xfs) {
+		if date, clock := b.dateFormat(b.xfs[xf]); date || clock {
+			return b.formatDate(f, date, clock)
+		}
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// 数字格式是否包含日期、时间部分
+func (b *xlsBook) dateFormat(ifmt uint16) (date, clock bool) {
+	switch {
+	//内置日期格式,27-36、50-58 为中文等区域的日期格式
+	case ifmt >= 14 && ifmt <= 17, ifmt >= 27 && ifmt <= 31, ifmt == 36, ifmt >= 50 && ifmt <= 58:
+		return true, false
+	case ifmt == 22:
+		return true, true
+	case ifmt >= 18 && ifmt <= 21, ifmt >= 32 && ifmt <= 35, ifmt >= 45 && ifmt <= 47:
+		return false, true
+	}
+	format, ok := b.formats[ifmt]
+	if !ok {
+		return false, false
+	}
+	//忽略引号中的文本、转义字符及 [Red] 等颜色、条件,保留 [h] [mm] [ss]
+	var buf strings.Builder
+	for i := 0; i < len(format); i++ {
+		switch c := format[i]; c {
+		case '"':
+			if j := strings.IndexByte(format[i+1:], '"'); j >= 0 {
+				i += j + 1
+			} else {
+				i = len(format)
+			}
+		case '\\', '_', '*':
+			i++
+		case '[':
+			j := strings.IndexByte(format[i:], ']')
+			if j < 0 {
+				i = len(format)
+				break
+			}
+			if tag := strings.ToLower(format[i+1 : i+j]); strings.Trim(tag, "hms") == "" {
+				buf.WriteString(tag)
+			}
+			i += j
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	s := strings.ToLower(buf.String())
+	//多段格式只看第一段
+	if i := strings.IndexByte(s, ';'); i >= 0 {
+		s = s[:i]
+	}
+	clock = strings.ContainsAny(s, "hs")
+	date = strings.ContainsAny(s, "yd") || (strings.Contains(s, "m") && !clock)
+	return date, clock
+}
+
+func (b *xlsBook) formatDate(f float64, date, clock bool) string {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if b.date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	} else if f < 61 {
+		//1900 年日期系统中不存在的 1900-02-29
+		base = base.AddDate(0, 0, 1)
+	}
+	t := base.Add(time.Duration(math.Round(f*86400)) * time.Second)
+	switch {
+	case date && clock:
+		return t.Format("2006-01-02 15:04:05")
+	case date:
+		return t.Format("2006-01-02")
+	}
+	return t.Format("15:04:05")
+}
@@ -0,0 +1,273 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+// 标签内容的文本形式,单元格以 | 分隔,每行一条
+func dumpSheet(t *testing.T, sheet SourceSheet) []string {
+	rows := make([]string, 0, sheet.MaxRow())
+	for r := 0; r < sheet.MaxRow(); r++ {
+		cells := make([]string, 0, sheet.MaxCol(r))
+		for c := 0; c < sheet.MaxCol(r); c++ {
+			s, err := sheet.Cell(r, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cells = append(cells, s)
+		}
+		rows = append(rows, strings.Join(cells, "|"))
+	}
+	return rows
+}
+
+// testdata/sample.xls: 共享字符串表按 48 字节拆分为多个 CONTINUE 记录,Workbook 流保存在 mini stream 中,
+// Extra 标签包含日期格式、布尔值、错误值、公式、嵌入的图表子流,Chart1 为图表标签
+func TestOpenXLS(t *testing.T) {
+	src, err := OpenSource("testdata/sample.xls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, sheet := range src.Sheets() {
+		names = append(names, sheet.Name())
+	}
+	if strings.Join(names, ",") != "Item,Extra" {
+		t.Fatalf("sheets = %v, want Item,Extra", names)
+	}
+	tests := []struct {
+		sheet string
+		rows  []string
+	}{
+		{"Item", []string{
+			"编号|名称|价格|数量|标记",
+			"int32|string|float64|[]int|bool",
+			"Id|Name|Price|Counts|Flag",
+			"1|短剑|12.5|1,2|true",
+			"2|跨越 CONTINUE 记录的长字符串 long string|0.01||false",
+			"3|mixed ascii 与中文|3.14159|-7|1",
+		}},
+		{"Extra", []string{
+			"2020-01-01|2020-01-01 12:00:00|18:00:00",
+			"TRUE|#N/A",
+			"公式结果|3.25",
+			"label|123.45",
+			"after chart",
+		}},
+	}
+	for _, tt := range tests {
+		sheet, ok := src.Sheet(tt.sheet)
+		if !ok {
+			t.Fatalf("no sheet %s", tt.sheet)
+		}
+		if got := dumpSheet(t, sheet); strings.Join(got, "\n") != strings.Join(tt.rows, "\n") {
+			t.Errorf("sheet %s:\n%s\nwant:\n%s", tt.sheet, strings.Join(got, "\n"), strings.Join(tt.rows, "\n"))
+		}
+	}
+}
+
+func TestXLSRK(t *testing.T) {
+	tests := []struct {
+		rk   uint32
+		want float64
+	}{
+		{0x00000002, 0},
+		{1<<2 | 2, 1},
+		{0xFFFFFFFC | 2, -1},
+		//最大、最小的 30 位整数
+		{0x7FFFFFFC | 2, 1<<29 - 1},
+		{0x80000000 | 2, -1 << 29},
+		{12345<<2 | 3, 123.45},
+		{0xFFFFFFFC | 3, -0.01},
+		//IEEE 754 双精度的高 30 位
+		{0x3FF80000, 1.5},
+		{0x3FF80000 | 1, 0.015},
+		{0xC0240000, -10},
+	}
+	for _, tt := range tests {
+		if got := xlsRK(tt.rk); got != tt.want {
+			t.Errorf("xlsRK(0x%08X) = %v, want %v", tt.rk, got, tt.want)
+		}
+	}
+}
+
+func TestXLSDateFormat(t *testing.T) {
+	b := &xlsBook{formats: map[uint16]string{
+		164: "yyyy/mm/dd",
+		165: "[h]:mm:ss",
+		166: `yyyy/mm/dd\ hh:mm;[Red]"x"`,
+		167: "mm:ss",
+		168: "mmm",
+		169: `"Day "0`,
+		170: `\d0.00`,
+		171: "0.00_);[Red](0.00)",
+		172: "[$-409]mmmm d, yyyy",
+		173: "[Red]General",
+		174: `0;"yyyy"`,
+		175: "[ss].00",
+	}}
+	tests := []struct {
+		ifmt        uint16
+		date, clock bool
+	}{
+		{0, false, false},
+		{2, false, false},
+		{14, true, false},
+		{22, true, true},
+		{20, false, true},
+		{31, true, false},
+		{32, false, true},
+		{47, false, true},
+		{57, true, false},
+		{164, true, false},
+		{165, false, true},
+		{166, true, true},
+		//m 与 h、s 同时出现时为分钟
+		{167, false, true},
+		{168, true, false},
+		{169, false, false},
+		{170, false, false},
+		{171, false, false},
+		{172, true, false},
+		{173, false, false},
+		{174, false, false},
+		{175, false, true},
+		//未定义的格式
+		{200, false, false},
+	}
+	for _, tt := range tests {
+		if date, clock := b.dateFormat(tt.ifmt); date != tt.date || clock != tt.clock {
+			t.Errorf("dateFormat(%d %q) = %v, %v, want %v, %v", tt.ifmt, b.formats[tt.ifmt], date, clock, tt.date, tt.clock)
+		}
+	}
+}
+
+func TestXLSFormatDate(t *testing.T) {
+	tests := []struct {
+		date1904    bool
+		f           float64
+		date, clock bool
+		want        string
+	}{
+		{false, 1, true, false, "1900-01-01"},
+		{false, 59, true, false, "1900-02-28"},
+		{false, 61, true, false, "1900-03-01"},
+		{false, 43831, true, false, "2020-01-01"},
+		{false, 43831.5, true, true, "2020-01-01 12:00:00"},
+		{false, 0.75, false, true, "18:00:00"},
+		//秒四舍五入
+		{false, 43831 + 1.4/86400, true, true, "2020-01-01 00:00:01"},
+		{false, 43831 + 59.6/86400, true, true, "2020-01-01 00:01:00"},
+		{true, 0, true, false, "1904-01-01"},
+		{true, 42369.25, true, true, "2020-01-01 06:00:00"},
+	}
+	for _, tt := range tests {
+		b := &xlsBook{date1904: tt.date1904}
+		if got := b.formatDate(tt.f, tt.date, tt.clock); got != tt.want {
+			t.Errorf("formatDate(%v, 1904=%v) = %s, want %s", tt.f, tt.date1904, got, tt.want)
+		}
+	}
+}
+
+// 以指定大小拆分为 BIFF 记录及其后的 CONTINUE 记录
+func biffStream(typ uint16, segs ...[]byte) []byte {
+	stream := make([]byte, 0)
+	for i, seg := range segs {
+		hdr := make([]byte, 4)
+		if i == 0 {
+			binary.LittleEndian.PutUint16(hdr, typ)
+		} else {
+			binary.LittleEndian.PutUint16(hdr, biffContinue)
+		}
+		binary.LittleEndian.PutUint16(hdr[2:], uint16(len(seg)))
+		stream = append(stream, hdr...)
+		stream = append(stream, seg...)
+	}
+	return stream
+}
+
+func TestBiffRecordUnicode(t *testing.T) {
+	zhong, wen := []byte{0x2D, 0x4E}, []byte{0x87, 0x65}
+	cat := func(parts ...[]byte) []byte {
+		data := make([]byte, 0)
+		for _, part := range parts {
+			data = append(data, part...)
+		}
+		return data
+	}
+	tests := []struct {
+		name string
+		cch  int
+		segs [][]byte
+		want string
+		err  string
+	}{
+		{"compressed", 3, [][]byte{[]byte("\x00abc")}, "abc", ""},
+		{"utf16", 2, [][]byte{cat([]byte{0x01}, zhong, wen)}, "中文", ""},
+		{"empty", 0, [][]byte{{0x00}}, "", ""},
+		{"compressed across continue", 4, [][]byte{[]byte("\x00ab"), []byte("\x00cd")}, "abcd", ""},
+		{"compressed to utf16", 3, [][]byte{[]byte("\x00ab"), cat([]byte{0x01}, zhong)}, "ab中", ""},
+		{"utf16 to compressed", 3, [][]byte{cat([]byte{0x01}, zhong, wen), []byte("\x00c")}, "中文c", ""},
+		{"three records", 3, [][]byte{cat([]byte{0x01}, zhong), []byte("\x00a"), cat([]byte{0x01}, wen)}, "中a文", ""},
+		//格式信息在最后一个字符之后,跨越 CONTINUE 时不再有压缩标志
+		{"rich text runs", 2, [][]byte{[]byte("\x08\x01\x00a"), []byte("\x00b\x01\x00"), []byte("\x02\x00")}, "ab", ""},
+		{"ext rst", 1, [][]byte{cat([]byte{0x05, 0x02, 0x00, 0x00, 0x00}, zhong, []byte{0xAA, 0xBB})}, "中", ""},
+		{"truncated", 3, [][]byte{[]byte("\x00ab")}, "", "unexpected end of record"},
+		{"truncated continue", 3, [][]byte{[]byte("\x00ab"), []byte("\x00")}, "", "unexpected end of record"},
+		//utf16 字符不能拆分到两个记录中
+		{"split utf16 char", 2, [][]byte{cat([]byte{0x01}, zhong, []byte{0x87}), []byte("\x01\x65")}, "", "invalid string"},
+		{"truncated runs", 1, [][]byte{[]byte("\x08\x01\x00a\x01\x00")}, "", "unexpected end of record"},
+	}
+	for _, tt := range tests {
+		rec, off, err := readBiffRecord(biffStream(biffSST, tt.segs...), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rec.segs) != len(tt.segs) {
+			t.Fatalf("%s: readBiffRecord segs = %d, want %d", tt.name, len(rec.segs), len(tt.segs))
+		}
+		s, err := rec.unicode(tt.cch)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: unicode err = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unicode err = %v", tt.name, err)
+			continue
+		}
+		if s != tt.want {
+			t.Errorf("%s: unicode = %q, want %q", tt.name, s, tt.want)
+		}
+		//所有内容均已读取
+		if rec.seg != len(rec.segs) || off != len(biffStream(biffSST, tt.segs...)) {
+			t.Errorf("%s: unicode stopped at segment %d pos %d", tt.name, rec.seg, rec.pos)
+		}
+	}
+}
+
+func TestParseXLSSST(t *testing.T) {
+	//cstUnique 远大于实际字符串数量时返回错误,不按其预分配
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint32(data[4:], math.MaxUint32)
+	data = append(data, 0x01, 0x00, 0x00, 'a')
+	rec, _, err := readBiffRecord(biffStream(biffSST, data), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &xlsBook{}
+	if err := b.parseSST(rec); err == nil || !strings.Contains(err.Error(), "unexpected end of record") {
+		t.Errorf("parseSST err = %v", err)
+	}
+	if len(b.sst) != 1 || b.sst[0] != "a" {
+		t.Errorf("parseSST sst = %q", b.sst)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"github.com/tealeg/xlsx"
+)
+
+func init() {
+	RegisterSource(".xlsx", false, openXLSX)
+}
+
+// excel 文件数据源
+type xlsxSource struct {
+	file *xlsx.File
+}
+
+type xlsxSheet struct {
+	sheet *xlsx.Sheet
+}
+
+func openXLSX(pathfile string) (Source, error) {
+	file, err := xlsx.OpenFile(pathfile)
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxSource{file: file}, nil
+}
+
+func (s *xlsxSource) Sheets() []SourceSheet {
+	sheets := make([]SourceSheet, 0, len(s.file.Sheets))
+	for _, sheet := range s.file.Sheets {
+		sheets = append(sheets, &xlsxSheet{sheet: sheet})
+	}
+	return sheets
+}
+
+func (s *xlsxSource) Sheet(name string) (SourceSheet, bool) {
+	sheet, ok := s.file.Sheet[name]
+	if !ok {
+		return nil, false
+	}
+	return &xlsxSheet{sheet: sheet}, true
+}
+
+func (s *xlsxSheet) Name() string {
+	return s.sheet.Name
+}
+
+func (s *xlsxSheet) MaxRow() int {
+	return len(s.sheet.Rows)
+}
+
+func (s *xlsxSheet) MaxCol(row int) int {
+	if row < 0 || row >= len(s.sheet.Rows) || s.sheet.Rows[row] == nil {
+		return 0
+	}
+	return len(s.sheet.Rows[row].Cells)
+}
+
+func (s *xlsxSheet) Cell(row, col int) (string, error) {
+	if col < 0 || col >= s.MaxCol(row) {
+		return "", nil
+	}
+	return s.sheet.Rows[row].Cells[col].FormattedValue()
+}
@@ -0,0 +1,310 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 字段数据类型分类
+type TypeKind int
+
+const (
+	//基础数据类型 int8、int16、int32、int64、int、float32、float64、string、bool
+	//及枚举 enum:E,枚举按 int32 保存
+	TypeScalar TypeKind = iota
+	//数组 []T
+	TypeSlice
+	//字典 map[K]V,K 只能是基础数据类型
+	TypeMap
+	//结构体,引用同名 excel 标签,单元格中填写该标签的主键
+	TypeStruct
+)
+
+// 基础数据类型
+var scalarTypes = map[string]bool{
+	"int8":    true,
+	"int16":   true,
+	"int32":   true,
+	"int64":   true,
+	"int":     true,
+	"float32": true,
+	"float64": true,
+	"string":  true,
+	"bool":    true,
+}
+
+// 表头第二行(字段数据类型)解析后的类型树
+// eg: map[int][]Item => {Map Key:{Scalar int} Elem:{Slice Elem:{Struct Item}}}
+type Type struct {
+	Kind TypeKind
+	//基础数据类型名称或结构体对应的标签名
+	Name string
+	//枚举名,定义枚举的同名标签见 Workbook.Enum
+	Enum string
+	//外键引用的标签名,值必须是该标签的主键,只输出值本身
+	Ref string
+	//map key 类型
+	Key *Type
+	//数组、map 元素类型
+	Elem *Type
+}
+
+// 解析字段数据类型
+// field := type [ "ref" ":" sheet ]
+// type := "[" "]" type | "map" "[" scalar "]" type | "enum" ":" name | scalar | struct
+// 外键引用作用于最内层的元素类型 eg: []int ref:Item  map[string]int ref:Item
+func ParseType(s string) (*Type, error) {
+	t, def, rest, err := parseColumnType(s)
+	if err != nil {
+		return nil, err
+	}
+	if def != "" || strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf(`unknown struct defined "%s"`, s)
+	}
+	return t, nil
+}
+
+// 解析表头第二行的字段数据类型
+// column := type [ "=" default ] [ "ref" ":" sheet ] { constraint }
+// 返回类型、默认值(空单元格使用的内容,含空格时用双引号包裹)及之后以空格分隔的部分(字段约束)
+// eg: int=5  []int=[1,2]  string="a b"
+func parseColumnType(s string) (t *Type, def string, rest string, err error) {
+	p := &typeParser{src: s}
+	if t, err = p.parse(); err != nil {
+		return nil, "", "", err
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '=' {
+		p.pos++
+		if def, err = p.defaultValue(); err != nil || def == "" {
+			return nil, "", "", fmt.Errorf(`invalid default value in "%s"`, s)
+		}
+	}
+	start := p.pos
+	if p.expect("ref") {
+		leaf := t.Leaf()
+		if !p.expect(":") || leaf.Kind != TypeScalar || leaf.Enum != "" {
+			return nil, "", "", fmt.Errorf(`invalid ref type "%s"`, s)
+		}
+		if leaf.Ref = p.ident(); leaf.Ref == "" {
+			return nil, "", "", fmt.Errorf(`invalid ref type "%s"`, s)
+		}
+	} else {
+		p.pos = start
+	}
+	rest = p.src[p.pos:]
+	if rest != "" && !unicode.IsSpace(rune(rest[0])) {
+		return nil, "", "", fmt.Errorf(`unknown struct defined "%s"`, s)
+	}
+	return t, def, rest, nil
+}
+
+type typeParser struct {
+	src string
+	pos int
+}
+
+func (p *typeParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *typeParser) expect(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *typeParser) ident() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+// 默认值,双引号包裹时按 golang 字符串解析,否则到空白字符为止
+func (p *typeParser) defaultValue() (string, error) {
+	rest := p.src[p.pos:]
+	if strings.HasPrefix(rest, `"`) {
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return "", err
+		}
+		p.pos += len(quoted)
+		return strconv.Unquote(quoted)
+	}
+	start := p.pos
+	for p.pos < len(p.src) && !unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos], nil
+}
+
+func (p *typeParser) parse() (*Type, error) {
+	if p.expect("[") {
+		if !p.expect("]") {
+			return nil, fmt.Errorf(`unknown struct defined "%s"`, p.src)
+		}
+		elem, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		return &Type{Kind: TypeSlice, Elem: elem}, nil
+	}
+	start := p.pos
+	name := p.ident()
+	if name == "map" {
+		if p.expect("[") {
+			key, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			if key.Kind != TypeScalar {
+				return nil, fmt.Errorf(`invalid map key type "%s" in "%s"`, key, p.src)
+			}
+			if !p.expect("]") {
+				return nil, fmt.Errorf(`unknown struct defined "%s"`, p.src)
+			}
+			elem, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			return &Type{Kind: TypeMap, Key: key, Elem: elem}, nil
+		}
+		//没有 [ 时 map 作为结构体标签名
+		p.pos = start
+		name = p.ident()
+	}
+	if name == "enum" {
+		if p.expect(":") {
+			enum := p.ident()
+			if enum == "" {
+				return nil, fmt.Errorf(`invalid enum type "%s"`, p.src)
+			}
+			return &Type{Kind: TypeScalar, Name: "int32", Enum: enum}, nil
+		}
+		p.pos = start
+		name = p.ident()
+	}
+	if name == "" {
+		return nil, fmt.Errorf(`unknown struct defined "%s"`, p.src)
+	}
+	if scalarTypes[name] {
+		return &Type{Kind: TypeScalar, Name: name}, nil
+	}
+	return &Type{Kind: TypeStruct, Name: name}, nil
+}
+
+// 类型的规范写法 eg: map[int][]Item
+func (t *Type) String() string {
+	if t.Enum != "" {
+		return "enum:" + t.Enum
+	}
+	if t.Ref != "" {
+		return t.Name + " ref:" + t.Ref
+	}
+	switch t.Kind {
+	case TypeSlice:
+		return "[]" + t.Elem.String()
+	case TypeMap:
+		return fmt.Sprintf("map[%s]%s", t.Key, t.Elem)
+	default:
+		return t.Name
+	}
+}
+
+// 对应的 golang 数据类型,结构体加 S_ 前缀,枚举为枚举名
+func (t *Type) GoType() string {
+	if t.Enum != "" {
+		return t.Enum
+	}
+	switch t.Kind {
+	case TypeSlice:
+		return "[]" + t.Elem.GoType()
+	case TypeMap:
+		return fmt.Sprintf("map[%s]%s", t.Key.GoType(), t.Elem.GoType())
+	case TypeStruct:
+		return "S_" + t.Name
+	default:
+		return t.Name
+	}
+}
+
+// 最内层的元素类型,结构体只会出现在最内层
+func (t *Type) Leaf() *Type {
+	for t.Kind == TypeSlice || t.Kind == TypeMap {
+		t = t.Elem
+	}
+	return t
+}
+
+// 类型中引用的枚举名,包括 map 的 key
+func (t *Type) enums() []string {
+	enums := make([]string, 0)
+	for ; t != nil; t = t.Elem {
+		if t.Key != nil && t.Key.Enum != "" {
+			enums = append(enums, t.Key.Enum)
+		}
+		if t.Enum != "" {
+			enums = append(enums, t.Enum)
+		}
+	}
+	return enums
+}
+
+// 是否是整数类型
+func (t *Type) IsInt() bool {
+	if t.Kind != TypeScalar {
+		return false
+	}
+	switch t.Name {
+	case "int8", "int16", "int32", "int64", "int":
+		return true
+	}
+	return false
+}
+
+// 是否是浮点数类型
+func (t *Type) IsFloat() bool {
+	return t.Kind == TypeScalar && (t.Name == "float32" || t.Name == "float64")
+}
+
+// 类型中是否只包含基础数据类型和数组(数据可单行输出)
+func (t *Type) IsFlat() bool {
+	switch t.Kind {
+	case TypeScalar:
+		return true
+	case TypeSlice:
+		return t.Elem.IsFlat()
+	}
+	return false
+}
+
+// 整数类型的位数
+func (t *Type) bitSize() int {
+	switch t.Name {
+	case "int8":
+		return 8
+	case "int16":
+		return 16
+	case "int32", "float32":
+		return 32
+	}
+	return 64
+}
@@ -0,0 +1,108 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseColumnType(t *testing.T) {
+	tests := []struct {
+		src  string
+		typ  string
+		def  string
+		rest string
+		err  string
+	}{
+		{src: "int32", typ: "int32"},
+		{src: "[]int8", typ: "[]int8"},
+		{src: "[][]string", typ: "[][]string"},
+		{src: "map[int][]Item", typ: "map[int][]Item"},
+		{src: "[]map[int][]Item", typ: "[]map[int][]Item"},
+		{src: "map[string]map[int]bool", typ: "map[string]map[int]bool"},
+		{src: "[ ] map [ int ] [ ] float32", typ: "[]map[int][]float32"},
+		//没有 [ 时 map、enum 作为结构体标签名
+		{src: "map", typ: "map"},
+		{src: "enum", typ: "enum"},
+		{src: "enum:Quality", typ: "enum:Quality"},
+		{src: "[]enum:Quality", typ: "[]enum:Quality"},
+		{src: "map[enum:Quality]int", typ: "map[enum:Quality]int"},
+		{src: "int32 ref:Item", typ: "int32 ref:Item"},
+		{src: "[]int32 ref:Item", typ: "[]int32 ref:Item"},
+		{src: "map[string]int ref:Item", typ: "map[string]int ref:Item"},
+		{src: "int32 ref:Item min=1", typ: "int32 ref:Item", rest: " min=1"},
+		{src: "int32 required unique", typ: "int32", rest: " required unique"},
+		{src: "int=5", typ: "int", def: "5"},
+		{src: "int=5 min=1", typ: "int", def: "5", rest: " min=1"},
+		{src: "[]int=[]", typ: "[]int", def: "[]"},
+		{src: "[]int=[1,2]", typ: "[]int", def: "[1,2]"},
+		{src: `string="a b" len<=5`, typ: "string", def: "a b", rest: " len<=5"},
+		{src: `string="a\"b"`, typ: "string", def: `a"b`},
+		{src: "int32=7 ref:Item", typ: "int32 ref:Item", def: "7"},
+
+		{src: "", err: "unknown struct defined"},
+		{src: "[int", err: "unknown struct defined"},
+		{src: "[]", err: "unknown struct defined"},
+		{src: "map[int", err: "unknown struct defined"},
+		{src: "map[[]int]int", err: `invalid map key type "[]int"`},
+		{src: "map[Item]int", err: `invalid map key type "Item"`},
+		{src: "enum:", err: "invalid enum type"},
+		{src: "int32;", err: "unknown struct defined"},
+		{src: "int32 ref:", err: "invalid ref type"},
+		{src: "int32 ref Item", err: "invalid ref type"},
+		{src: "enum:Quality ref:Item", err: "invalid ref type"},
+		{src: "[]Item ref:Item", err: "invalid ref type"},
+		{src: "int=", err: "invalid default value"},
+		{src: `string="abc`, err: "invalid default value"},
+		{src: `string="a"b`, err: "unknown struct defined"},
+	}
+	for _, tt := range tests {
+		typ, def, rest, err := parseColumnType(tt.src)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseColumnType(%q) err = %v, want %q", tt.src, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseColumnType(%q) err = %v", tt.src, err)
+			continue
+		}
+		if typ.String() != tt.typ || def != tt.def || rest != tt.rest {
+			t.Errorf("parseColumnType(%q) = %q, %q, %q, want %q, %q, %q", tt.src, typ, def, rest, tt.typ, tt.def, tt.rest)
+		}
+	}
+}
+
+func TestParseTypeTree(t *testing.T) {
+	typ, err := ParseType("[]map[int][]Item")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ.Kind != TypeSlice || typ.Elem.Kind != TypeMap || typ.Elem.Key.Name != "int" ||
+		typ.Elem.Elem.Kind != TypeSlice || typ.Elem.Elem.Elem.Kind != TypeStruct || typ.Elem.Elem.Elem.Name != "Item" {
+		t.Errorf("ParseType tree = %+v", typ)
+	}
+	if leaf := typ.Leaf(); leaf.Kind != TypeStruct || leaf.Name != "Item" {
+		t.Errorf("Leaf() = %s, want Item", leaf)
+	}
+	if got := typ.GoType(); got != "[]map[int][]S_Item" {
+		t.Errorf("GoType() = %s", got)
+	}
+	typ, err = ParseType("map[enum:Quality][]enum:Kind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enums := strings.Join(typ.enums(), ","); enums != "Quality,Kind" {
+		t.Errorf("enums() = %s, want Quality,Kind", enums)
+	}
+	//ParseType 不允许默认值及约束
+	for _, src := range []string{"int=5", "int required"} {
+		if _, err := ParseType(src); err == nil {
+			t.Errorf("ParseType(%q) want error", src)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TypeScript number 可以精确表示的最大整数 Number.MAX_SAFE_INTEGER
+const tsMaxSafeInteger = 1<<53 - 1
+
+// 对应的 TypeScript 类型,int64 为 bigint,其他数字为 number,map 的 key 保持原类型
+func tsType(t *Type) string {
+	switch t.Kind {
+	case TypeSlice:
+		return fmt.Sprintf("ReadonlyArray<%s>", tsType(t.Elem))
+	case TypeMap:
+		return fmt.Sprintf("ReadonlyMap<%s, %s>", tsType(t.Key), tsType(t.Elem))
+	case TypeStruct:
+		return "S_" + t.Name
+	}
+	switch t.Name {
+	case "string":
+		return "string"
+	case "bool":
+		return "boolean"
+	case "int64":
+		if t.Enum == "" {
+			return "bigint"
+		}
+	}
+	return "number"
+}
+
+// 输出标签对应的 interface,返回需要继续输出的子标签
+func generateTSFromXLSXFile(sheet *Sheet, outputf func(s string), parsedSheetMap map[string]bool) (addParseSheetArray []string) {
+	outputf(fmt.Sprintf("\nexport interface S_%s {\n", sheet.Name))
+	for _, field := range sheet.Fields {
+		if desc := strings.TrimSpace(field.Desc); desc != "" {
+			outputf(fmt.Sprintf("\t/** %s */\n", strings.Replace(strings.Replace(desc, "*/", "*\\/", -1), "\n", " ", -1)))
+		}
+		optional := ""
+		if field.Optional() {
+			optional = "?"
+		}
+		outputf(fmt.Sprintf("\treadonly P_%s%s: %s;\n", field.Name, optional, tsType(field.Type)))
+		if leaf := field.Type.Leaf(); leaf.Kind == TypeStruct && !parsedSheetMap[leaf.Name] {
+			parsedSheetMap[leaf.Name] = true
+			addParseSheetArray = append(addParseSheetArray, leaf.Name)
+		}
+	}
+	outputf("}\n")
+	return
+}
+
+// 输出标签数据 export const SF_<标签名>: ReadonlyMap<主键类型, S_<标签名>>
+// 组合主键逐层嵌套 eg: ReadonlyMap<number, ReadonlyMap<number, S_X>>
+// 导入数据中 new Map<...> 使用到的所有子标签类型
+func (g *Generator) generateTSData(sheet *Sheet, records []*Record, outputf func(s string), typesModule string) {
+	types := map[string]bool{sheet.Name: true}
+	entries := make([]string, 0, len(records))
+	for _, node := range keyTree(records, 0) {
+		entries = append(entries, tsKeyEntry(sheet, node, 0, types))
+	}
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, "S_"+name)
+	}
+	sort.Strings(names)
+	mapType := tsKeyMapType(sheet, 0)
+	outputf(fmt.Sprintf("import { %s } from './%s';\n\n", strings.Join(names, ", "), typesModule))
+	outputf(fmt.Sprintf("export const SF_%s: Readonly%s = new %s([\n", sheet.Name, mapType, mapType))
+	for _, entry := range entries {
+		outputf(fmt.Sprintf("%s%s,\n", g.Indent, entry))
+	}
+	outputf("]);\n")
+}
+
+// 从第 depth 个主键开始的 Map 类型
+func tsKeyMapType(sheet *Sheet, depth int) string {
+	elem := "S_" + sheet.Name
+	if depth < len(sheet.Keys)-1 {
+		elem = "Readonly" + tsKeyMapType(sheet, depth+1)
+	}
+	return fmt.Sprintf("Map<%s, %s>", tsType(sheet.Keys[depth].Type), elem)
+}
+
+func tsKeyEntry(sheet *Sheet, node *keyNode, depth int, types map[string]bool) string {
+	if node.Record != nil {
+		return fmt.Sprintf("[%s, %s]", tsValue(node.Key, types), tsRecord(node.Record, types))
+	}
+	entries := make([]string, 0, len(node.Children))
+	for _, child := range node.Children {
+		entries = append(entries, tsKeyEntry(sheet, child, depth+1, types))
+	}
+	return fmt.Sprintf("[%s, new %s([%s])]", tsValue(node.Key, types), tsKeyMapType(sheet, depth+1), strings.Join(entries, ", "))
+}
+
+// 未设置的可选字段省略
+func tsRecord(record *Record, types map[string]bool) string {
+	var buf strings.Builder
+	buf.WriteString("{")
+	first := true
+	for i, field := range record.Sheet.Fields {
+		if record.Values[i] == nil {
+			continue
+		}
+		if !first {
+			buf.WriteString(", ")
+		}
+		first = false
+		buf.WriteString(fmt.Sprintf("P_%s: %s", field.Name, tsValue(record.Values[i], types)))
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// 数据转换为 TypeScript 表达式,map 转换为 Map,key 按类型输出,int64 输出为 bigint eg: 1n
+// Map 类型参数中使用的子标签类型记录到 types
+func tsValue(v *Value, types map[string]bool) string {
+	switch v.Type.Kind {
+	case TypeScalar:
+		if s, ok := v.Scalar.(string); ok {
+			return jsonQuote(s)
+		}
+		if tsType(v.Type) == "bigint" {
+			return v.String() + "n"
+		}
+		return v.String()
+	case TypeStruct:
+		return tsRecord(v.Record, types)
+	case TypeSlice:
+		elems := make([]string, 0, len(v.List))
+		for _, elem := range v.List {
+			elems = append(elems, tsValue(elem, types))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	entries := make([]string, 0, len(v.List))
+	for i, elem := range v.List {
+		entries = append(entries, fmt.Sprintf("[%s, %s]", tsValue(v.Keys[i], types), tsValue(elem, types)))
+	}
+	if leaf := v.Type.Elem.Leaf(); leaf.Kind == TypeStruct {
+		types[leaf.Name] = true
+	}
+	mapType := fmt.Sprintf("Map<%s, %s>", tsType(v.Type.Key), tsType(v.Type.Elem))
+	if len(entries) == 0 {
+		return fmt.Sprintf("new %s()", mapType)
+	}
+	return fmt.Sprintf("new %s([%s])", mapType, strings.Join(entries, ", "))
+}
+
+// 输出 index.ts,导出所有类型及模板数据
+func generateTSIndex(outputf func(s string), typesModules []string, sheetNames []string) {
+	outputf("//Code generated by xlsx-parser.\n")
+	outputf("//source: github.com/zxfonline/xlsx_parser\n")
+	outputf("//DO NOT EDIT!\n\n")
+	for _, module := range typesModules {
+		outputf(fmt.Sprintf("export * from './%s';\n", module))
+	}
+	for _, sheetName := range sheetNames {
+		outputf(fmt.Sprintf("export * from './sample_%s';\n", sheetName))
+	}
+}
+
+// 检查输出 TypeScript 的标签
+func (g *Generator) checkTSSheets(workbooks map[string]*Workbook, check func(pathfile string) bool) {
+	for pathfile, sheetNames := range g.Excels {
+		wb := workbooks[pathfile]
+		if !check(pathfile) || wb == nil {
+			continue
+		}
+		for _, sheetName := range g.tsSheets(sheetNames) {
+			if sheet, ok := wb.sheets[sheetName]; ok {
+				records, _ := sheet.Records()
+				checkTSNumbers(records)
+			}
+		}
+	}
+}
+
+// 检查输出为 number 的整数是否超出 Number.MAX_SAFE_INTEGER,超出时精度丢失,需要使用 int64
+// 错误记录到所在单元格,存在错误时返回 errInvalid
+func checkTSNumbers(records []*Record) error {
+	var rerr error
+	for _, record := range records {
+		for i, field := range record.Sheet.Fields {
+			if v := record.Values[i]; v != nil && !tsSafeNumber(v) {
+				record.Sheet.report(record.Row, field, fmt.Errorf("integer out of TypeScript number range ±%d,use int64", int64(tsMaxSafeInteger)))
+				rerr = errInvalid
+			}
+		}
+	}
+	return rerr
+}
+
+func tsSafeNumber(v *Value) bool {
+	switch v.Type.Kind {
+	case TypeScalar:
+		i, ok := v.Scalar.(int64)
+		return !ok || tsType(v.Type) == "bigint" || (i <= tsMaxSafeInteger && i >= -tsMaxSafeInteger)
+	case TypeStruct:
+		for _, elem := range v.Record.Values {
+			if elem != nil && !tsSafeNumber(elem) {
+				return false
+			}
+		}
+		return true
+	}
+	for i, elem := range v.List {
+		if !tsSafeNumber(elem) || (v.Type.Kind == TypeMap && !tsSafeNumber(v.Keys[i])) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateTSData(t *testing.T) {
+	dir := writeTestDir(t, map[string]string{
+		"Item":   "ID,奖励,列表,空\nint32,map[int]Reward,[]Goods,map[int][]Buff\nID,Drops,List,Empty\n1,1=1,1,\n",
+		"Reward": "ID,数量\nint32,int\nID,Num\n1,10\n",
+		"Goods":  "ID\nint32\nID\n1\n",
+		"Buff":   "ID\nint32\nID\n1\n",
+	})
+	g := newTestGenerator()
+	wb, err := g.openWorkbook(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := wb.Sheet("Item")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := sheet.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	g.generateTSData(sheet, records, func(s string) { buf.WriteString(s) }, "file_d")
+	//数组中的子标签为对象字面量,不需要导入
+	want := "import { S_Buff, S_Item, S_Reward } from './file_d';\n\n" +
+		"export const SF_Item: ReadonlyMap<number, S_Item> = new Map<number, S_Item>([\n" +
+		"\t[1, {P_ID: 1, P_Drops: new Map<number, S_Reward>([[1, {P_ID: 1, P_Num: 10}]]), P_List: [{P_ID: 1}], P_Empty: new Map<number, ReadonlyArray<S_Buff>>()}],\n" +
+		"]);\n"
+	if buf.String() != want {
+		t.Errorf("generateTSData:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
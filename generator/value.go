@@ -0,0 +1,272 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 单元格按字段类型解析后的数据
+type Value struct {
+	Type *Type
+	//基础数据类型的值 int64、float64、string、bool
+	Scalar interface{}
+	//数组元素或 map 的值
+	List []*Value
+	//map 的 key,与 List 一一对应
+	Keys []*Value
+	//结构体引用的数据行
+	Record *Record
+}
+
+// 基础数据类型的文本形式,用作主键索引及 map key
+func (v *Value) String() string {
+	switch s := v.Scalar.(type) {
+	case float64:
+		return strconv.FormatFloat(s, 'g', -1, 64)
+	default:
+		return fmt.Sprintf("%v", s)
+	}
+}
+
+// 解析基础数据类型,空字符串为零值
+func (wb *Workbook) decodeScalar(t *Type, s string) (*Value, error) {
+	v := &Value{Type: t}
+	switch {
+	case t.Enum != "":
+		//单元格中填写枚举名称,保存为枚举值
+		s = strings.TrimSpace(s)
+		if s == "" {
+			v.Scalar = int64(0)
+			break
+		}
+		enum, err := wb.Enum(t.Enum)
+		if err != nil {
+			return nil, err
+		}
+		i, err := enum.Lookup(s)
+		if err != nil {
+			return nil, err
+		}
+		v.Scalar = i
+	case t.IsInt():
+		s = strings.TrimSpace(s)
+		if s == "" {
+			v.Scalar = int64(0)
+			break
+		}
+		i, err := strconv.ParseInt(s, 10, t.bitSize())
+		if err != nil {
+			//excel 中的整数可能以浮点数形式保存
+			f, ferr := strconv.ParseFloat(s, 64)
+			if ferr != nil || f != math.Trunc(f) {
+				return nil, fmt.Errorf("invalid %s value %q", t, s)
+			}
+			if i, err = strconv.ParseInt(strconv.FormatFloat(f, 'f', -1, 64), 10, t.bitSize()); err != nil {
+				return nil, fmt.Errorf("invalid %s value %q", t, s)
+			}
+		}
+		v.Scalar = i
+	case t.IsFloat():
+		s = strings.TrimSpace(s)
+		if s == "" {
+			v.Scalar = float64(0)
+			break
+		}
+		//float32 只检查取值范围,保留表格中的精度
+		if _, err := strconv.ParseFloat(s, t.bitSize()); err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", t, s)
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("invalid %s value %q", t, s)
+		}
+		v.Scalar = f
+	case t.Name == "bool":
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "1", "true", "t", "on", "yes", "y":
+			v.Scalar = true
+		case "", "0", "false", "f", "off", "no", "n":
+			v.Scalar = false
+		default:
+			return nil, fmt.Errorf("invalid bool value %q", s)
+		}
+	default:
+		v.Scalar = s
+	}
+	return v, nil
+}
+
+// 按字段类型解析单元格内容
+// 数组元素以 ArraySeparator 分隔,map 元素为 key MapSeparator value 并以 ArraySeparator 分隔,
+// 嵌套在数组、map 中的数组、map 需要用 ArraysTokenBegin、ArraysTokenEnd 包裹
+// eg: [][]int => [1,2],[3,4]  map[int][]int => 1=[1,2],2=[3]  []map[string]int => [a=1,b=2],[c=3]
+func (wb *Workbook) decode(t *Type, s string, nested bool) (*Value, error) {
+	switch t.Kind {
+	case TypeScalar:
+		if nested {
+			s = strings.TrimSpace(s)
+		}
+		return wb.decodeScalar(t, s)
+	case TypeStruct:
+		s = strings.TrimSpace(s)
+		sheet, err := wb.Sheet(t.Name)
+		if err != nil {
+			return nil, err
+		}
+		record, err := sheet.Lookup(s)
+		if err != nil {
+			return nil, err
+		}
+		return &Value{Type: t, Record: record}, nil
+	case TypeSlice:
+		v := &Value{Type: t, List: make([]*Value, 0)}
+		items, err := wb.split(s, t.Elem)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item, err = wb.unwrap(t.Elem, item); err != nil {
+				return nil, err
+			}
+			elem, err := wb.decode(t.Elem, item, true)
+			if err != nil {
+				return nil, err
+			}
+			v.List = append(v.List, elem)
+		}
+		return v, nil
+	case TypeMap:
+		v := &Value{Type: t, List: make([]*Value, 0), Keys: make([]*Value, 0)}
+		items, err := wb.split(s, t)
+		if err != nil {
+			return nil, err
+		}
+		hash := make(map[string]bool)
+		for _, item := range items {
+			idx := strings.Index(item, wb.g.MapSeparator)
+			if idx == -1 {
+				return nil, fmt.Errorf("format error,no found map separator %q in %q", wb.g.MapSeparator, item)
+			}
+			key, err := wb.decodeScalar(t.Key, strings.TrimSpace(item[:idx]))
+			if err != nil {
+				return nil, err
+			}
+			if hash[key.String()] {
+				return nil, fmt.Errorf("duplicate map key's value %q", key)
+			}
+			hash[key.String()] = true
+			value, err := wb.unwrap(t.Elem, item[idx+len(wb.g.MapSeparator):])
+			if err != nil {
+				return nil, err
+			}
+			elem, err := wb.decode(t.Elem, value, true)
+			if err != nil {
+				return nil, err
+			}
+			v.Keys = append(v.Keys, key)
+			v.List = append(v.List, elem)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unknown type %s", t)
+}
+
+// 解析字段的默认值,数组、map 的默认值可以用 ArraysTokenBegin、ArraysTokenEnd 包裹 eg: []int=[] []int=[1,2]
+func (wb *Workbook) decodeDefault(field *Field) (*Value, error) {
+	def := field.Default
+	//[1],[2] 去掉外层后不匹配,不是包裹的写法
+	if s, err := wb.unwrap(field.Type, def); err == nil {
+		if _, err := wb.split(s, field.Type); err == nil {
+			def = s
+		}
+	}
+	v, err := wb.decode(field.Type, def, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default value %q,err:%v", field.Default, err)
+	}
+	return v, nil
+}
+
+// 检查字段的默认值,结构体引用的数据行在使用时检查
+func (s *Sheet) checkDefault(field *Field) error {
+	if field.Default == "" {
+		return nil
+	}
+	if c := field.Constraints; c != nil && (c.Optional || c.Required) {
+		return fmt.Errorf("optional or required field can not have default value")
+	}
+	if field.Type.Leaf().Kind == TypeStruct {
+		return nil
+	}
+	v, err := s.wb.decodeDefault(field)
+	if err != nil {
+		return err
+	}
+	if field.Constraints != nil {
+		if errs := field.Constraints.check(field.Default, v); len(errs) > 0 {
+			return fmt.Errorf("invalid default value %q,err:%v", field.Default, errs[0])
+		}
+	}
+	return nil
+}
+
+// 以 ArraySeparator 分隔最外层元素,空单元格没有元素,字符串以外的空元素忽略
+func (wb *Workbook) split(s string, elem *Type) ([]string, error) {
+	begin, end, sep := wb.g.ArraysTokenBegin, wb.g.ArraysTokenEnd, wb.g.ArraySeparator
+	items := make([]string, 0)
+	depth, last := 0, 0
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], begin):
+			depth++
+			i += len(begin)
+		case strings.HasPrefix(s[i:], end):
+			if depth--; depth < 0 {
+				return nil, fmt.Errorf("format error,unexpected %q in %q", end, s)
+			}
+			i += len(end)
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			items = append(items, s[last:i])
+			i += len(sep)
+			last = i
+		default:
+			i++
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("format error,unclosed %q in %q", begin, s)
+	}
+	items = append(items, s[last:])
+	if strings.TrimSpace(s) == "" {
+		return items[:0], nil
+	}
+	if elem.Kind == TypeScalar && elem.Name == "string" {
+		return items, nil
+	}
+	result := items[:0]
+	for _, item := range items {
+		if strings.TrimSpace(item) != "" {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// 嵌套的数组、map 去掉外层 ArraysTokenBegin、ArraysTokenEnd
+func (wb *Workbook) unwrap(t *Type, s string) (string, error) {
+	if t.Kind != TypeSlice && t.Kind != TypeMap {
+		return s, nil
+	}
+	s = strings.TrimSpace(s)
+	begin, end := wb.g.ArraysTokenBegin, wb.g.ArraysTokenEnd
+	if !strings.HasPrefix(s, begin) || !strings.HasSuffix(s, end) || len(s) < len(begin)+len(end) {
+		return "", fmt.Errorf("format error,%s value %q must be enclosed in %s%s", t, s, begin, end)
+	}
+	return s[len(begin) : len(s)-len(end)], nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestGenerator() *Generator {
+	return &Generator{Config: *DefaultConfig(), diag: &Diagnostics{}}
+}
+
+// 以 csv 内容创建只有一个标签的 workbook,文件名即标签名
+func openTestWorkbook(t *testing.T, g *Generator, sheetName, content string) *Workbook {
+	dir, err := ioutil.TempDir("", "xlsx_parser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	pathfile := filepath.Join(dir, sheetName+".csv")
+	if err := ioutil.WriteFile(pathfile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wb, err := g.openWorkbook(pathfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wb
+}
+
+func TestDecode(t *testing.T) {
+	wb := &Workbook{g: newTestGenerator()}
+	tests := []struct {
+		typ  string
+		cell string
+		want string
+		err  string
+	}{
+		{typ: "int32", cell: " 12 ", want: "12"},
+		{typ: "int32", cell: "", want: "0"},
+		{typ: "int64", cell: "3.0", want: "3"},
+		{typ: "int8", cell: "128", err: `invalid int8 value "128"`},
+		{typ: "int", cell: "1.5", err: `invalid int value "1.5"`},
+		{typ: "float32", cell: "0.1", want: "0.1"},
+		{typ: "float32", cell: "1e39", err: `invalid float32 value "1e39"`},
+		{typ: "float64", cell: "NaN", err: `invalid float64 value "NaN"`},
+		{typ: "bool", cell: "Yes", want: "true"},
+		{typ: "bool", cell: "", want: "false"},
+		{typ: "bool", cell: "2", err: `invalid bool value "2"`},
+		{typ: "string", cell: " a b ", want: `" a b "`},
+		{typ: "[]int", cell: "", want: "[]"},
+		{typ: "[]int", cell: "1, 2,,3", want: "[1, 2, 3]"},
+		{typ: "[]string", cell: "a,,b", want: `["a", "", "b"]`},
+		{typ: "[]int", cell: "1,x", err: `invalid int value "x"`},
+		{typ: "[][]int", cell: "[1,2],[3]", want: "[[1, 2], [3]]"},
+		{typ: "[][]int", cell: "[1,2],3", err: "must be enclosed in []"},
+		{typ: "[][]int", cell: "[1,2", err: `unclosed "["`},
+		{typ: "[]int", cell: "1]", err: `unexpected "]"`},
+		{typ: "map[int]string", cell: "1=a,2=b", want: `{1="a", 2="b"}`},
+		{typ: "map[int]string", cell: "1=a,1=b", err: `duplicate map key's value "1"`},
+		{typ: "map[int]string", cell: "1", err: `no found map separator "="`},
+		{typ: "map[string][]int", cell: "a=[1,2],b=[]", want: `{"a"=[1, 2], "b"=[]}`},
+		{typ: "[]map[int][]int", cell: "[1=[1,2],2=[3]],[]", want: "[{1=[1, 2], 2=[3]}, {}]"},
+		{typ: "[]map[int][]int", cell: "[1=[x]]", err: `invalid int value "x"`},
+	}
+	for _, tt := range tests {
+		typ, err := ParseType(tt.typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := wb.decode(typ, tt.cell, false)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("decode(%s, %q) err = %v, want %q", tt.typ, tt.cell, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("decode(%s, %q) err = %v", tt.typ, tt.cell, err)
+			continue
+		}
+		if got := diffValue(v); got != tt.want {
+			t.Errorf("decode(%s, %q) = %s, want %s", tt.typ, tt.cell, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeDiagnostics(t *testing.T) {
+	g := newTestGenerator()
+	wb := openTestWorkbook(t, g, "Item", strings.Join([]string{
+		"编号,列表,字典,下一个",
+		"int32,[]int,map[int]string,Item optional",
+		"Id,List,Map,Next",
+		`1,"1,2",1=a,`,
+		`2,"1,x",1=a,1`,
+		`3,,"1=a,1=b",9`,
+	}, "\n"))
+	sheet, err := wb.Sheet("Item")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := sheet.Records()
+	if err != errInvalid {
+		t.Fatalf("Records() err = %v, want errInvalid", err)
+	}
+	if len(records) != 1 || diffRecord(records[0]) != `{Id: 1, List: [1, 2], Map: {1="a"}, Next: nil}` {
+		t.Fatalf("Records() = %d records", len(records))
+	}
+	file := wb.Path
+	want := []string{
+		file + `!Item!B5 (column List, type []int): invalid int value "x"`,
+		file + `!Item!C6 (column Map, type map[int]string): duplicate map key's value "1"`,
+		file + `!Item!D6 (column Next, type Item): no found key "9" in sheet Item`,
+	}
+	list := g.diag.List()
+	got := make([]string, 0, len(list))
+	for _, d := range list {
+		got = append(got, d.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("diagnostics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCellName(t *testing.T) {
+	tests := []struct {
+		row, col int
+		want     string
+	}{
+		{0, 0, "A1"},
+		{16, 2, "C17"},
+		{9, 25, "Z10"},
+		{0, 26, "AA1"},
+		{99, 701, "ZZ100"},
+		{0, 702, "AAA1"},
+	}
+	for _, tt := range tests {
+		if got := CellName(tt.row, tt.col); got != tt.want {
+			t.Errorf("CellName(%d, %d) = %s, want %s", tt.row, tt.col, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//合并连续文件变化的等待时间,excel 保存时会多次写入、重命名临时文件
+	watchDelay = 500 * time.Millisecond
+	//轮询检查文件变化的间隔
+	pollInterval = time.Second
+)
+
+// 监听目录中的文件变化
+type watcher interface {
+	//增加监听的目录,已监听的目录忽略
+	Add(dir string) error
+	//发生变化的文件路径
+	Events() <-chan string
+	Close() error
+}
+
+// 监听excel文件,保存后重新检查并导出,错误信息输出到 out,ctx 取消时返回
+// 根据增量导出缓存只重新解析、导出发生变化的excel文件
+// poll 为 true 时使用轮询(网络共享目录等不支持 inotify 的场景)
+func Watch(ctx context.Context, cfg *Config, poll bool, out io.Writer) error {
+	g, err := NewGenerator(cfg)
+	if err != nil {
+		return err
+	}
+	var w watcher
+	if poll {
+		w = newPollWatcher(pollInterval)
+	} else {
+		w = newWatcher()
+	}
+	defer w.Close()
+	run := func(files []string) {
+		if len(files) > 0 {
+			fmt.Fprintf(out, "[%s] changed: %s\n", time.Now().Format("15:04:05"), strings.Join(files, ", "))
+		}
+		err := g.Generate(ctx)
+		//只有第一次导出忽略缓存
+		g.Force = false
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(out, "[%s] export failed\n%v\n", time.Now().Format("15:04:05"), err)
+		} else {
+			fmt.Fprintf(out, "[%s] export ok\n", time.Now().Format("15:04:05"))
+		}
+		for _, dir := range g.watchDirs() {
+			if err := w.Add(dir); err != nil {
+				fmt.Fprintf(out, "watch %s,err:%v\n", dir, err)
+			}
+		}
+	}
+	run(nil)
+	pending := make(map[string]bool)
+	timer := time.NewTimer(watchDelay)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case pathfile, ok := <-w.Events():
+			if !ok {
+				return fmt.Errorf("watcher closed")
+			}
+			if !watchable(pathfile) {
+				continue
+			}
+			pending[CleanPath(pathfile)] = true
+			timer.Reset(watchDelay)
+		case <-timer.C:
+			files := make([]string, 0, len(pending))
+			for pathfile := range pending {
+				files = append(files, pathfile)
+			}
+			sort.Strings(files)
+			pending = make(map[string]bool)
+			run(files)
+		}
+	}
+}
+
+// 是否为需要处理的文件变化,忽略 excel 的临时文件 ~$xxx.xlsx 及其他文件
+func watchable(pathfile string) bool {
+	name := filepath.Base(pathfile)
+	return !strings.HasPrefix(name, "~$") && workbookExts[strings.ToLower(filepath.Ext(name))]
+}
+
+// 需要监听的目录: excel文件所在目录、csv 等单标签文件所在目录、自动查找的目录
+// 新增的子目录在下一次导出后加入监听
+func (g *Generator) watchDirs() []string {
+	dirs := make(map[string]bool)
+	for pathfile := range g.Excels {
+		if info, err := os.Stat(pathfile); err == nil && info.IsDir() {
+			dirs[pathfile] = true
+		} else {
+			dirs[filepath.Dir(pathfile)] = true
+		}
+	}
+	for _, input := range g.Inputs {
+		input = CleanPath(input)
+		if info, err := os.Stat(input); err != nil || !info.IsDir() {
+			continue
+		}
+		filepath.Walk(input, func(pathfile string, info os.FileInfo, err error) error {
+			if err == nil && info.IsDir() {
+				dirs[pathfile] = true
+			}
+			return nil
+		})
+	}
+	result := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		result = append(result, dir)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// 文件修改时间及大小,轮询时比较
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// 定时扫描目录检查文件变化,所有平台可用
+type pollWatcher struct {
+	mu     sync.Mutex
+	dirs   map[string]map[string]fileStamp
+	events chan string
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newPollWatcher(interval time.Duration) *pollWatcher {
+	w := &pollWatcher{
+		dirs:   make(map[string]map[string]fileStamp),
+		events: make(chan string),
+		done:   make(chan struct{}),
+	}
+	go w.run(interval)
+	return w
+}
+
+func scanDir(dir string) (map[string]fileStamp, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	stamps := make(map[string]fileStamp, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			stamps[entry.Name()] = fileStamp{modTime: entry.ModTime(), size: entry.Size()}
+		}
+	}
+	return stamps, nil
+}
+
+func (w *pollWatcher) Add(dir string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.dirs[dir]; ok {
+		return nil
+	}
+	stamps, err := scanDir(dir)
+	if err != nil {
+		return err
+	}
+	w.dirs[dir] = stamps
+	return nil
+}
+
+func (w *pollWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *pollWatcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	return nil
+}
+
+func (w *pollWatcher) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		for _, pathfile := range w.scan() {
+			select {
+			case w.events <- pathfile:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// 扫描所有目录,返回新增、修改及删除的文件
+func (w *pollWatcher) scan() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	changed := make([]string, 0)
+	for dir, old := range w.dirs {
+		stamps, err := scanDir(dir)
+		if err != nil {
+			//目录被删除时保留之前的记录,重新创建后可以检查到变化
+			continue
+		}
+		for name, stamp := range stamps {
+			if prev, ok := old[name]; !ok || !prev.modTime.Equal(stamp.modTime) || prev.size != stamp.size {
+				changed = append(changed, filepath.Join(dir, name))
+			}
+		}
+		for name := range old {
+			if _, ok := stamps[name]; !ok {
+				changed = append(changed, filepath.Join(dir, name))
+			}
+		}
+		w.dirs[dir] = stamps
+	}
+	return changed
+}
@@ -0,0 +1,123 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// linux 使用 inotify 监听,不可用时(如达到 max_user_instances 上限)使用轮询
+func newWatcher() watcher {
+	w, err := newInotifyWatcher()
+	if err != nil {
+		return newPollWatcher(pollInterval)
+	}
+	return w
+}
+
+// excel 保存时写入临时文件后重命名,监听目录而不是文件
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM | syscall.IN_DELETE
+
+type inotifyWatcher struct {
+	fd int
+	//非阻塞的 fd,通过 runtime poller 读取,Close 时读取返回错误
+	file *os.File
+	mu   sync.Mutex
+	//watch descriptor -> 目录
+	dirs   map[int32]string
+	paths  map[string]bool
+	events chan string
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newInotifyWatcher() (*inotifyWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	w := &inotifyWatcher{
+		fd:     fd,
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		dirs:   make(map[int32]string),
+		paths:  make(map[string]bool),
+		events: make(chan string),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *inotifyWatcher) Add(dir string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.paths[dir] {
+		return nil
+	}
+	wd, err := syscall.InotifyAddWatch(w.fd, dir, inotifyMask)
+	if err != nil {
+		return err
+	}
+	w.dirs[int32(wd)] = dir
+	w.paths[dir] = true
+	return nil
+}
+
+func (w *inotifyWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *inotifyWatcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.file.Close()
+	})
+	return err
+}
+
+func (w *inotifyWatcher) run() {
+	defer close(w.events)
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			end := off + syscall.SizeofInotifyEvent + int(event.Len)
+			if end > n {
+				break
+			}
+			name := strings.TrimRight(string(buf[off+syscall.SizeofInotifyEvent:end]), "\x00")
+			off = end
+			w.mu.Lock()
+			dir, ok := w.dirs[event.Wd]
+			if event.Mask&syscall.IN_IGNORED != 0 {
+				//目录被删除,重新导出后再次加入监听
+				delete(w.dirs, event.Wd)
+				delete(w.paths, dir)
+			}
+			w.mu.Unlock()
+			if !ok || name == "" {
+				continue
+			}
+			select {
+			case w.events <- filepath.Join(dir, name):
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2016 zxfonline@sina.com. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package generator
+
+// 非 linux 平台使用轮询
+func newWatcher() watcher {
+	return newPollWatcher(pollInterval)
+}